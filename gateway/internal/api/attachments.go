@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"ledger/domain"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAttachmentSize — наибольший размер загружаемого файла.
+const maxAttachmentSize = 10 << 20
+
+// allowedAttachmentTypes — типы вложений: фото и сканы чеков. Тип определяется
+// по содержимому, а не по заголовку клиента.
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"image/gif":       true,
+	"application/pdf": true,
+}
+
+// UploadAttachment принимает multipart/form-data с файлом в поле file.
+func (h *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	transactionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	// Запас на заголовки multipart поверх размера файла
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentSize+64<<10)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, `{"error":"expected multipart/form-data with a file field"}`, http.StatusBadRequest)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			http.Error(w, `{"error":"file field is required"}`, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			h.handleUploadError(w, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(part, maxAttachmentSize+1))
+		part.Close()
+		if err != nil {
+			h.handleUploadError(w, err)
+			return
+		}
+		if len(content) > maxAttachmentSize {
+			http.Error(w, `{"error":"file is too large, max 10 MB"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		h.storeAttachment(w, r, transactionID, part.FileName(), content)
+		return
+	}
+}
+
+func (h *Handler) storeAttachment(w http.ResponseWriter, r *http.Request, transactionID int, filename string, content []byte) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil || !allowedAttachmentTypes[contentType] {
+		http.Error(w, `{"error":"unsupported file type, expected JPEG, PNG, WebP, GIF or PDF"}`, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	response, err := h.ledgerService.UploadAttachment(r.Context(), domain.UploadAttachmentRequest{
+		TransactionID: transactionID,
+		Filename:      filename,
+		ContentType:   contentType,
+		Content:       bytes.NewReader(content),
+	})
+	if err != nil {
+		h.handleTransactionError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/transactions/%d/attachments/%d", transactionID, response.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toAttachmentResponse(*response))
+}
+
+func (h *Handler) handleUploadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, `{"error":"file is too large, max 10 MB"}`, http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, `{"error":"invalid multipart body"}`, http.StatusBadRequest)
+}
+
+func (h *Handler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	transactionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	responses, err := h.ledgerService.ListAttachments(r.Context(), transactionID)
+	if err != nil {
+		h.handleTransactionError(w, err)
+		return
+	}
+
+	apiResponses := make([]AttachmentResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = toAttachmentResponse(response)
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+// DownloadAttachment отдаёт файл с исходным именем как вложение, чтобы браузер не открывал его на месте.
+func (h *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	transactionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	id, _ := strconv.Atoi(mux.Vars(r)["attachmentID"])
+
+	response, content, err := h.ledgerService.OpenAttachment(r.Context(), transactionID, id)
+	if err != nil {
+		h.handleTransactionError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", response.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(response.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": response.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, content)
+}
+
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	transactionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	id, _ := strconv.Atoi(mux.Vars(r)["attachmentID"])
+
+	if err := h.ledgerService.DeleteAttachment(r.Context(), transactionID, id); err != nil {
+		h.handleTransactionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAttachmentResponse(response domain.AttachmentResponse) AttachmentResponse {
+	return AttachmentResponse{
+		ID:            response.ID,
+		TransactionID: response.TransactionID,
+		Filename:      response.Filename,
+		ContentType:   response.ContentType,
+		Size:          response.Size,
+		SHA256:        response.SHA256,
+		CreatedAt:     response.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
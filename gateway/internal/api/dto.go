@@ -1,33 +1,332 @@
 package api
 
+import "ledger/domain"
+
 type CreateTransactionRequest struct {
-	Amount      float64 `json:"amount"`
-	Category    string  `json:"category"`
-	Description string  `json:"description"`
-	Date        string  `json:"date"`
+	Type           string       `json:"type"`
+	Amount         domain.Money `json:"amount"`
+	Currency       string       `json:"currency"`
+	Category       string       `json:"category"`
+	Description    string       `json:"description"`
+	Date           string       `json:"date"`
+	Tags           []string     `json:"tags"`
+	Override       bool         `json:"override"`
+	OverrideReason string       `json:"override_reason"`
+}
+
+// PatchTransactionRequest — частичное изменение: отсутствующие поля не меняются.
+type PatchTransactionRequest struct {
+	Type           *string       `json:"type"`
+	Amount         *domain.Money `json:"amount"`
+	Currency       *string       `json:"currency"`
+	Category       *string       `json:"category"`
+	Description    *string       `json:"description"`
+	Date           *string       `json:"date"`
+	Tags           *[]string     `json:"tags"`
+	Override       bool          `json:"override"`
+	OverrideReason string        `json:"override_reason"`
 }
 
 type TransactionResponse struct {
-	ID          int     `json:"id"`
-	Amount      float64 `json:"amount"`
+	ID           int                     `json:"id"`
+	Type         string                  `json:"type"`
+	Amount       domain.Money            `json:"amount"`
+	Currency     string                  `json:"currency"`
+	Category     string                  `json:"category"`
+	Description  string                  `json:"description"`
+	Date         string                  `json:"date"`
+	Tags         []string                `json:"tags"`
+	OverBudget   bool                    `json:"over_budget"`
+	ReversesID   *int                    `json:"reverses_id,omitempty"`
+	ReversedByID *int                    `json:"reversed_by_id,omitempty"`
+	SplitID      *int                    `json:"split_id,omitempty"`
+	Warnings     []BudgetWarningResponse `json:"warnings,omitempty"`
+}
+
+type TransactionPageResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+type SearchResultResponse struct {
+	Transaction TransactionResponse `json:"transaction"`
+	Rank        float64             `json:"rank"`
+	Headline    string              `json:"headline"`
+}
+
+type CreateSplitRequest struct {
+	Amount         domain.Money       `json:"amount"`
+	Currency       string             `json:"currency"`
+	Description    string             `json:"description"`
+	Date           string             `json:"date"`
+	Tags           []string           `json:"tags"`
+	Override       bool               `json:"override"`
+	OverrideReason string             `json:"override_reason"`
+	Lines          []SplitLineRequest `json:"lines"`
+}
+
+type SplitLineRequest struct {
+	Category    string       `json:"category"`
+	Amount      domain.Money `json:"amount"`
+	Description string       `json:"description"`
+}
+
+type SplitResponse struct {
+	ID          int                     `json:"id"`
+	Amount      domain.Money            `json:"amount"`
+	Currency    string                  `json:"currency"`
+	Description string                  `json:"description"`
+	Date        string                  `json:"date"`
+	Lines       []TransactionResponse   `json:"lines"`
+	Warnings    []BudgetWarningResponse `json:"warnings,omitempty"`
+}
+
+type TransactionSnapshotResponse struct {
+	Type        string       `json:"type,omitempty"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency,omitempty"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Date        string       `json:"date"`
+	Tags        []string     `json:"tags,omitempty"`
+}
+
+type TransactionChangeResponse struct {
+	Action    string                       `json:"action"`
+	Before    *TransactionSnapshotResponse `json:"before"`
+	After     *TransactionSnapshotResponse `json:"after"`
+	CreatedAt string                       `json:"created_at"`
+}
+
+type BudgetWarningResponse struct {
 	Category    string  `json:"category"`
-	Description string  `json:"description"`
-	Date        string  `json:"date"`
+	Threshold   float64 `json:"threshold"`
+	PercentUsed float64 `json:"percent_used"`
 }
 
 type CreateBudgetRequest struct {
-	Category string  `json:"category"`
-	Limit    float64 `json:"limit"`
-	Period   string  `json:"period"`
+	Category          string       `json:"category"`
+	Limit             domain.Money `json:"limit"`
+	Currency          string       `json:"currency"`
+	Period            string       `json:"period"`
+	Rollover          bool         `json:"rollover"`
+	WarningThresholds []float64    `json:"warning_thresholds"`
+	OverspendPolicy   string       `json:"overspend_policy"`
+	EffectiveFrom     string       `json:"effective_from"`
 }
 
 type BudgetResponse struct {
-	Category string  `json:"category"`
-	Limit    float64 `json:"limit"`
-	Period   string  `json:"period"`
+	Category          string                `json:"category"`
+	Limit             domain.Money          `json:"limit"`
+	EffectiveLimit    domain.Money          `json:"effective_limit"`
+	Currency          string                `json:"currency"`
+	Period            string                `json:"period"`
+	Rollover          bool                  `json:"rollover"`
+	WarningThresholds []float64             `json:"warning_thresholds"`
+	OverspendPolicy   string                `json:"overspend_policy"`
+	Status            *BudgetStatusResponse `json:"status,omitempty"`
+}
+
+type BudgetStatusResponse struct {
+	Category    string       `json:"category"`
+	Period      string       `json:"period"`
+	Limit       domain.Money `json:"limit"`
+	Spent       domain.Money `json:"spent"`
+	Remaining   domain.Money `json:"remaining"`
+	Currency    string       `json:"currency"`
+	PercentUsed float64      `json:"percent_used"`
+	PeriodStart string       `json:"period_start"`
+	PeriodEnd   string       `json:"period_end"`
+	DaysLeft    int          `json:"days_left"`
+}
+
+type BudgetVersionResponse struct {
+	Period        string       `json:"period"`
+	Limit         domain.Money `json:"limit"`
+	EffectiveFrom *string      `json:"effective_from"`
+	CreatedAt     string       `json:"created_at"`
+}
+
+type CreateLimitOverrideRequest struct {
+	Kind     string       `json:"kind"`
+	Amount   domain.Money `json:"amount"`
+	StartsOn string       `json:"starts_on"`
+	EndsOn   string       `json:"ends_on"`
+	Reason   string       `json:"reason"`
+}
+
+type LimitOverrideResponse struct {
+	ID        int          `json:"id"`
+	Period    string       `json:"period"`
+	Kind      string       `json:"kind"`
+	Amount    domain.Money `json:"amount"`
+	StartsOn  string       `json:"starts_on"`
+	EndsOn    string       `json:"ends_on"`
+	Reason    string       `json:"reason"`
+	Active    bool         `json:"active"`
+	CreatedAt string       `json:"created_at"`
+}
+
+type BudgetHistoryResponse struct {
+	Versions  []BudgetVersionResponse `json:"versions"`
+	Overrides []LimitOverrideResponse `json:"overrides"`
+}
+
+type BudgetSuggestionsRequest struct {
+	Period     string   `json:"period"`
+	Periods    int      `json:"periods"`
+	Percentile float64  `json:"percentile"`
+	Categories []string `json:"categories"`
+	Date       string   `json:"date"`
 }
 
-type SpendingSummaryResponse map[string]float64
+type PeriodAmountResponse struct {
+	PeriodStart string       `json:"period_start"`
+	Amount      domain.Money `json:"amount"`
+}
+
+type BudgetSuggestionResponse struct {
+	Category   string                 `json:"category"`
+	Period     string                 `json:"period"`
+	Limit      domain.Money           `json:"limit"`
+	Percentile float64                `json:"percentile"`
+	Basis      []PeriodAmountResponse `json:"basis"`
+	Outliers   []PeriodAmountResponse `json:"outliers"`
+}
+
+type CreateBudgetPoolRequest struct {
+	Name       string       `json:"name"`
+	Categories []string     `json:"categories"`
+	Limit      domain.Money `json:"limit"`
+	Period     string       `json:"period"`
+}
+
+type BudgetPoolResponse struct {
+	Name       string       `json:"name"`
+	Categories []string     `json:"categories"`
+	Limit      domain.Money `json:"limit"`
+	Period     string       `json:"period"`
+}
+
+type SpendingSummaryResponse map[string]domain.Money
+
+type CategorySpendingResponse struct {
+	Category string                     `json:"category"`
+	Parent   string                     `json:"parent,omitempty"`
+	Own      domain.Money               `json:"own"`
+	Total    domain.Money               `json:"total"`
+	Children []CategorySpendingResponse `json:"children,omitempty"`
+}
+
+type BudgetForecastResponse struct {
+	Category            string       `json:"category"`
+	Period              string       `json:"period"`
+	Limit               domain.Money `json:"limit"`
+	Spent               domain.Money `json:"spent"`
+	PeriodStart         string       `json:"period_start"`
+	PeriodEnd           string       `json:"period_end"`
+	ElapsedDays         int          `json:"elapsed_days"`
+	TotalDays           int          `json:"total_days"`
+	DailyVelocity       domain.Money `json:"daily_velocity"`
+	IdealSpent          domain.Money `json:"ideal_spent"`
+	ProjectedTotal      domain.Money `json:"projected_total"`
+	ProjectedExhaustion *string      `json:"projected_exhaustion"`
+	Status              string       `json:"status"`
+}
+
+type OverspendResponse struct {
+	Transaction    TransactionResponse `json:"transaction"`
+	BudgetCategory string              `json:"budget_category"`
+	Policy         string              `json:"policy"`
+	Reason         string              `json:"reason"`
+	Limit          domain.Money        `json:"limit"`
+	Spent          domain.Money        `json:"spent"`
+	CreatedAt      string              `json:"created_at"`
+}
+
+type TagSpendingResponse struct {
+	Tag          string       `json:"tag"`
+	Amount       domain.Money `json:"amount"`
+	Transactions int          `json:"transactions"`
+}
+
+type CashFlowResponse struct {
+	Income      domain.Money `json:"income"`
+	Expenses    domain.Money `json:"expenses"`
+	Refunds     domain.Money `json:"refunds"`
+	NetSpending domain.Money `json:"net_spending"`
+	Net         domain.Money `json:"net"`
+	Currency    string       `json:"currency"`
+}
+
+type CreateExchangeRateRequest struct {
+	Currency     string  `json:"currency"`
+	BaseCurrency string  `json:"base_currency"`
+	Date         string  `json:"date"`
+	Rate         float64 `json:"rate"`
+}
+
+type ExchangeRateResponse struct {
+	Currency     string  `json:"currency"`
+	BaseCurrency string  `json:"base_currency"`
+	Date         string  `json:"date"`
+	Rate         float64 `json:"rate"`
+}
+
+type CreateRecurringRequest struct {
+	Type        string       `json:"type"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Tags        []string     `json:"tags"`
+	Frequency   string       `json:"frequency"`
+	Interval    int          `json:"interval"`
+	DayOfMonth  int          `json:"day_of_month"`
+	StartsOn    string       `json:"starts_on"`
+	EndsOn      string       `json:"ends_on"`
+}
+
+type RecurringResponse struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Tags        []string     `json:"tags"`
+	Frequency   string       `json:"frequency"`
+	Interval    int          `json:"interval"`
+	DayOfMonth  int          `json:"day_of_month,omitempty"`
+	StartsOn    string       `json:"starts_on"`
+	EndsOn      string       `json:"ends_on,omitempty"`
+	Active      bool         `json:"active"`
+}
+
+type OccurrenceResponse struct {
+	ID            int    `json:"id"`
+	RecurringID   int    `json:"recurring_id"`
+	DueOn         string `json:"due_on"`
+	Status        string `json:"status"`
+	TransactionID *int   `json:"transaction_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type AttachmentResponse struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Filename      string `json:"filename"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type CategoryResponse struct {
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+}
 
 type BulkTransactionResponse struct {
 	Total    int                     `json:"total"`
@@ -3,14 +3,20 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"ledger/domain"
 	"ledger/service"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gorilla/mux"
 )
 
+const budgetWarningHeader = "X-Budget-Warning"
+
 type Handler struct {
 	ledgerService service.LedgerService
 }
@@ -33,9 +39,14 @@ func (h *Handler) CreateTransactionHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	domainReq := domain.CreateTransactionRequest{
-		Amount:      req.Amount,
-		Category:    req.Category,
-		Description: req.Description,
+		Type:           req.Type,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Category:       req.Category,
+		Description:    req.Description,
+		Tags:           req.Tags,
+		Override:       req.Override,
+		OverrideReason: req.OverrideReason,
 	}
 
 	if req.Date != "" {
@@ -52,45 +63,200 @@ func (h *Handler) CreateTransactionHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	apiResponse := TransactionResponse{
-		ID:          response.ID,
-		Amount:      response.Amount,
-		Category:    response.Category,
-		Description: response.Description,
-		Date:        response.Date.Format("2006-01-02 15:04:05"),
+	apiResponse := toTransactionResponse(*response)
+
+	for _, warning := range response.Warnings {
+		apiResponse.Warnings = append(apiResponse.Warnings, BudgetWarningResponse{
+			Category:    warning.Category,
+			Threshold:   warning.Threshold,
+			PercentUsed: warning.PercentUsed,
+		})
+		// Категория может быть не в ASCII, поэтому в заголовок попадают только числа
+		w.Header().Add(budgetWarningHeader, fmt.Sprintf("threshold=%g; used=%.2f", warning.Threshold, warning.PercentUsed))
 	}
 
+	w.Header().Set("Location", fmt.Sprintf("/api/transactions/%d", response.ID))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(apiResponse)
 }
 
+func (h *Handler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	response, err := h.ledgerService.GetTransaction(r.Context(), id)
+	if err != nil {
+		h.handleTransactionError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(toTransactionResponse(*response))
+}
+
+// ListTransactions отдаёт страницу транзакций. На маршруте /categories/{category}/transactions
+// категория берётся из пути, остальные фильтры — из параметров запроса.
 func (h *Handler) ListTransactions(w http.ResponseWriter, r *http.Request) {
 	if r.Context().Err() != nil {
 		return
 	}
 
-	responses, err := h.ledgerService.ListTransactions(r.Context())
+	query := r.URL.Query()
+	category, ok := mux.Vars(r)["category"]
+	if !ok {
+		category = query.Get("category")
+	}
+
+	req := domain.ListTransactionsRequest{
+		Category:    category,
+		Description: query.Get("description"),
+		Tags:        parseTags(query.Get("tags")),
+		Sort:        query.Get("sort"),
+		Cursor:      query.Get("cursor"),
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := parseTransactionDate(value)
+		if err != nil {
+			http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		req.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := parseTransactionDate(value)
+		if err != nil {
+			http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		// Дата без времени включает весь день
+		if len(value) == len("2006-01-02") {
+			to = to.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		}
+		req.To = &to
+	}
+	if value := query.Get("min_amount"); value != "" {
+		amount, err := domain.ParseMoney(value)
+		if err != nil {
+			http.Error(w, `{"error":"invalid min_amount"}`, http.StatusBadRequest)
+			return
+		}
+		req.MinAmount = &amount
+	}
+	if value := query.Get("max_amount"); value != "" {
+		amount, err := domain.ParseMoney(value)
+		if err != nil {
+			http.Error(w, `{"error":"invalid max_amount"}`, http.StatusBadRequest)
+			return
+		}
+		req.MaxAmount = &amount
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			http.Error(w, `{"error":"limit must be a positive integer"}`, http.StatusBadRequest)
+			return
+		}
+		req.Limit = limit
+	}
+
+	page, err := h.ledgerService.ListTransactions(r.Context(), req)
 	if err != nil {
-		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponse := TransactionPageResponse{
+		Transactions: make([]TransactionResponse, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+	for i, response := range page.Transactions {
+		apiResponse.Transactions[i] = toTransactionResponse(response)
+	}
+
+	json.NewEncoder(w).Encode(apiResponse)
+}
+
+// SearchTransactions ищет по описаниям; q разбирается как веб-запрос ("фраза", or, -слово).
+func (h *Handler) SearchTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	query := r.URL.Query()
+	req := domain.SearchTransactionsRequest{
+		Query:    query.Get("q"),
+		Category: query.Get("category"),
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := parseTransactionDate(value)
+		if err != nil {
+			http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		req.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := parseTransactionDate(value)
+		if err != nil {
+			http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		if len(value) == len("2006-01-02") {
+			to = to.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		}
+		req.To = &to
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			http.Error(w, `{"error":"limit must be a positive integer"}`, http.StatusBadRequest)
+			return
+		}
+		req.Limit = limit
+	}
+
+	responses, err := h.ledgerService.SearchTransactions(r.Context(), req)
+	if err != nil {
+		h.handleServiceError(w, err)
 		return
 	}
 
-	apiResponses := make([]TransactionResponse, len(responses))
+	apiResponses := make([]SearchResultResponse, len(responses))
 	for i, response := range responses {
-		apiResponses[i] = TransactionResponse{
-			ID:          response.ID,
-			Amount:      response.Amount,
-			Category:    response.Category,
-			Description: response.Description,
-			Date:        response.Date.Format("2006-01-02 15:04:05"),
+		apiResponses[i] = SearchResultResponse{
+			Transaction: toTransactionResponse(response.Transaction),
+			Rank:        response.Rank,
+			Headline:    response.Headline,
 		}
 	}
 
 	json.NewEncoder(w).Encode(apiResponses)
 }
 
-func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
-	var req CreateBudgetRequest
+func toTransactionResponse(response domain.TransactionResponse) TransactionResponse {
+	return TransactionResponse{
+		ID:           response.ID,
+		Type:         response.Type,
+		Amount:       response.Amount,
+		Currency:     response.Currency,
+		Category:     response.Category,
+		Description:  response.Description,
+		Date:         response.Date.Format("2006-01-02 15:04:05"),
+		Tags:         response.Tags,
+		OverBudget:   response.OverBudget,
+		ReversesID:   response.ReversesID,
+		ReversedByID: response.ReversedByID,
+		SplitID:      response.SplitID,
+	}
+}
+
+// ReplaceTransaction (PUT) задаёт все поля транзакции; без даты сохраняется прежняя.
+func (h *Handler) ReplaceTransaction(w http.ResponseWriter, r *http.Request) {
+	var req CreateTransactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
 		return
@@ -100,140 +266,1288 @@ func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Преобразуем в доменный DTO
-	domainReq := domain.CreateBudgetRequest{
-		Category: req.Category,
-		Limit:    req.Limit,
-		Period:   req.Period,
+	domainReq := domain.UpdateTransactionRequest{
+		Type:           &req.Type,
+		Amount:         &req.Amount,
+		Currency:       &req.Currency,
+		Category:       &req.Category,
+		Description:    &req.Description,
+		Tags:           &req.Tags,
+		Override:       req.Override,
+		OverrideReason: req.OverrideReason,
 	}
 
-	response, err := h.ledgerService.CreateBudget(r.Context(), domainReq)
+	if req.Date != "" {
+		date, err := parseTransactionDate(req.Date)
+		if err != nil {
+			http.Error(w, `{"error":"invalid date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		domainReq.Date = &date
+	}
+
+	h.updateTransaction(w, r, domainReq)
+}
+
+// PatchTransaction (PATCH) меняет только переданные поля.
+func (h *Handler) PatchTransaction(w http.ResponseWriter, r *http.Request) {
+	var req PatchTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	domainReq := domain.UpdateTransactionRequest{
+		Type:           req.Type,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Category:       req.Category,
+		Description:    req.Description,
+		Tags:           req.Tags,
+		Override:       req.Override,
+		OverrideReason: req.OverrideReason,
+	}
+
+	if req.Date != nil {
+		date, err := parseTransactionDate(*req.Date)
+		if err != nil {
+			http.Error(w, `{"error":"invalid date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		domainReq.Date = &date
+	}
+
+	h.updateTransaction(w, r, domainReq)
+}
+
+func (h *Handler) updateTransaction(w http.ResponseWriter, r *http.Request, req domain.UpdateTransactionRequest) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	response, err := h.ledgerService.UpdateTransaction(r.Context(), id, req)
 	if err != nil {
-		h.handleServiceError(w, err)
+		h.handleTransactionError(w, err)
 		return
 	}
 
-	apiResponse := BudgetResponse{
-		Category: response.Category,
-		Limit:    response.Limit,
-		Period:   response.Period,
+	apiResponse := toTransactionResponse(*response)
+	for _, warning := range response.Warnings {
+		apiResponse.Warnings = append(apiResponse.Warnings, BudgetWarningResponse{
+			Category:    warning.Category,
+			Threshold:   warning.Threshold,
+			PercentUsed: warning.PercentUsed,
+		})
+		w.Header().Add(budgetWarningHeader, fmt.Sprintf("threshold=%g; used=%.2f", warning.Threshold, warning.PercentUsed))
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(apiResponse)
 }
 
-func (h *Handler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+// DeleteTransaction удаляет транзакцию мягко (204) или, с ?mode=reversal, создаёт сторнирующую запись (201).
+func (h *Handler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Context().Err() != nil {
 		return
 	}
 
-	responses, err := h.ledgerService.ListBudgets(r.Context())
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	reversal, err := h.ledgerService.DeleteTransaction(r.Context(), id, r.URL.Query().Get("mode"))
 	if err != nil {
-		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+		h.handleTransactionError(w, err)
 		return
 	}
 
-	apiResponses := make([]BudgetResponse, len(responses))
+	if reversal == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toTransactionResponse(*reversal))
+}
+
+func (h *Handler) GetTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	responses, err := h.ledgerService.GetTransactionHistory(r.Context(), id)
+	if err != nil {
+		h.handleTransactionError(w, err)
+		return
+	}
+
+	apiResponses := make([]TransactionChangeResponse, len(responses))
 	for i, response := range responses {
-		apiResponses[i] = BudgetResponse{
-			Category: response.Category,
-			Limit:    response.Limit,
-			Period:   response.Period,
+		apiResponses[i] = TransactionChangeResponse{
+			Action:    response.Action,
+			Before:    toTransactionSnapshotResponse(response.Before),
+			After:     toTransactionSnapshotResponse(response.After),
+			CreatedAt: response.CreatedAt.Format("2006-01-02 15:04:05"),
 		}
 	}
 
 	json.NewEncoder(w).Encode(apiResponses)
 }
 
-func (h *Handler) Ping(w http.ResponseWriter, r *http.Request) {
-	// Проверяем, не отменен ли уже контекст
+// CreateSplit разносит один платёж по нескольким категориям; платёж принимается
+// или отклоняется целиком.
+func (h *Handler) CreateSplit(w http.ResponseWriter, r *http.Request) {
+	var req CreateSplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
 	if r.Context().Err() != nil {
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"message": "pong"})
+	domainReq := domain.CreateSplitRequest{
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Description:    req.Description,
+		Tags:           req.Tags,
+		Override:       req.Override,
+		OverrideReason: req.OverrideReason,
+		Lines:          make([]domain.SplitLineRequest, len(req.Lines)),
+	}
+
+	for i, line := range req.Lines {
+		domainReq.Lines[i] = domain.SplitLineRequest{
+			Category:    line.Category,
+			Amount:      line.Amount,
+			Description: line.Description,
+		}
+	}
+
+	if req.Date != "" {
+		date, err := parseTransactionDate(req.Date)
+		if err != nil {
+			http.Error(w, `{"error":"invalid date format, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"}`, http.StatusBadRequest)
+			return
+		}
+		domainReq.Date = date
+	}
+
+	response, err := h.ledgerService.CreateSplit(r.Context(), domainReq)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponse := toSplitResponse(*response)
+
+	for _, warning := range response.Warnings {
+		apiResponse.Warnings = append(apiResponse.Warnings, BudgetWarningResponse{
+			Category:    warning.Category,
+			Threshold:   warning.Threshold,
+			PercentUsed: warning.PercentUsed,
+		})
+		w.Header().Add(budgetWarningHeader, fmt.Sprintf("threshold=%g; used=%.2f", warning.Threshold, warning.PercentUsed))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(apiResponse)
 }
 
-func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetSplit(w http.ResponseWriter, r *http.Request) {
 	if r.Context().Err() != nil {
 		return
 	}
 
-	if err := h.ledgerService.HealthCheck(r.Context()); err != nil {
-		http.Error(w, `{"error":"service unavailable"}`, http.StatusServiceUnavailable)
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	response, err := h.ledgerService.GetSplit(r.Context(), id)
+	if err != nil {
+		h.handleTransactionError(w, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+	json.NewEncoder(w).Encode(toSplitResponse(*response))
 }
 
-func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
-	errorMsg := err.Error()
+func (h *Handler) DeleteSplit(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
 
-	switch errorMsg {
-	case "budget not found":
-		http.Error(w, `{"error":"budget not found"}`, http.StatusBadRequest)
-	case "budget exceeded":
-		http.Error(w, `{"error":"budget exceeded"}`, http.StatusConflict)
-	case "validation failed: amount must be positive",
-		"validation failed: category is required":
-		http.Error(w, `{"error":"`+errorMsg+`"}`, http.StatusBadRequest)
-	default:
-		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if err := h.ledgerService.DeleteSplit(r.Context(), id); err != nil {
+		h.handleTransactionError(w, err)
+		return
 	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *Handler) TimeoutTest(w http.ResponseWriter, r *http.Request) {
-	time.Sleep(10 * time.Second)
+func toSplitResponse(response domain.SplitResponse) SplitResponse {
+	lines := make([]TransactionResponse, len(response.Lines))
+	for i, line := range response.Lines {
+		lines[i] = toTransactionResponse(line)
+	}
 
-	json.NewEncoder(w).Encode(map[string]string{"status": "completed after 10s"})
+	return SplitResponse{
+		ID:          response.ID,
+		Amount:      response.Amount,
+		Currency:    response.Currency,
+		Description: response.Description,
+		Date:        response.Date.Format("2006-01-02 15:04:05"),
+		Lines:       lines,
+	}
 }
 
-func (h *Handler) GetSpendingSummary(w http.ResponseWriter, r *http.Request) {
+func toTransactionSnapshotResponse(snapshot *domain.TransactionSnapshot) *TransactionSnapshotResponse {
+	if snapshot == nil {
+		return nil
+	}
+	return &TransactionSnapshotResponse{
+		Type:        snapshot.Type,
+		Amount:      snapshot.Amount,
+		Currency:    snapshot.Currency,
+		Category:    snapshot.Category,
+		Description: snapshot.Description,
+		Date:        snapshot.Date.Format("2006-01-02 15:04:05"),
+		Tags:        snapshot.Tags,
+	}
+}
+
+// parseTags разбирает метки, перечисленные через запятую.
+func parseTags(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func parseTransactionDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02 15:04:05", value); err == nil {
+		return date, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func (h *Handler) handleTransactionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTransactionNotFound):
+		http.Error(w, `{"error":"transaction not found"}`, http.StatusNotFound)
+	case errors.Is(err, domain.ErrTransactionReversed):
+		http.Error(w, `{"error":"transaction is reversed and cannot be changed"}`, http.StatusConflict)
+	case errors.Is(err, domain.ErrSplitLine):
+		http.Error(w, `{"error":"transaction is a split line and can only be deleted with its split"}`, http.StatusConflict)
+	case errors.Is(err, domain.ErrSplitNotFound):
+		http.Error(w, `{"error":"split not found"}`, http.StatusNotFound)
+	case errors.Is(err, domain.ErrAttachmentNotFound):
+		http.Error(w, `{"error":"attachment not found"}`, http.StatusNotFound)
+	default:
+		h.handleServiceError(w, err)
+	}
+}
+
+func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var req CreateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
 	if r.Context().Err() != nil {
 		return
 	}
 
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
+	// Преобразуем в доменный DTO
+	domainReq := domain.CreateBudgetRequest{
+		Category:          req.Category,
+		Limit:             req.Limit,
+		Currency:          req.Currency,
+		Period:            req.Period,
+		Rollover:          req.Rollover,
+		WarningThresholds: req.WarningThresholds,
+		OverspendPolicy:   req.OverspendPolicy,
+	}
 
-	if fromStr == "" || toStr == "" {
-		http.Error(w, `{"error":"both from and to parameters are required"}`, http.StatusBadRequest)
-		return
+	if req.EffectiveFrom != "" {
+		effectiveFrom, err := time.Parse("2006-01-02", req.EffectiveFrom)
+		if err != nil {
+			http.Error(w, `{"error":"invalid effective_from date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+			return
+		}
+		domainReq.EffectiveFrom = &effectiveFrom
 	}
 
-	from, err := time.Parse("2006-01-02", fromStr)
+	response, err := h.ledgerService.CreateBudget(r.Context(), domainReq)
 	if err != nil {
-		http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		h.handleServiceError(w, err)
 		return
 	}
 
-	to, err := time.Parse("2006-01-02", toStr)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toBudgetResponse(*response))
+}
+
+func (h *Handler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	req := domain.ListBudgetsRequest{
+		WithStatus: r.URL.Query().Get("with_status") == "true",
+	}
+
+	responses, err := h.ledgerService.ListBudgets(r.Context(), req)
 	if err != nil {
-		http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
 		return
 	}
 
-	to = to.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+	apiResponses := make([]BudgetResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = toBudgetResponse(response)
+	}
 
-	req := domain.GetSpendingSummaryRequest{
-		From: from,
-		To:   to,
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func (h *Handler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
 	}
 
-	summary, err := h.ledgerService.GetSpendingSummary(r.Context(), req)
+	category, ok := budgetCategory(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.ledgerService.GetBudget(r.Context(), category)
 	if err != nil {
-		h.handleReportServiceError(w, err)
+		h.handleBudgetLookupError(w, err)
 		return
 	}
 
-	if summary == nil {
-		summary = make(domain.SpendingSummary)
+	json.NewEncoder(w).Encode(toBudgetResponse(*response))
+}
+
+func (h *Handler) GetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(summary)
+	category, ok := budgetCategory(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.ledgerService.GetBudgetStatus(r.Context(), category)
+	if err != nil {
+		h.handleBudgetLookupError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(toBudgetStatusResponse(*response))
+}
+
+func (h *Handler) GetBudgetHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	category, ok := budgetCategory(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.ledgerService.GetBudgetHistory(r.Context(), category)
+	if err != nil {
+		h.handleBudgetLookupError(w, err)
+		return
+	}
+
+	apiResponse := BudgetHistoryResponse{
+		Versions:  make([]BudgetVersionResponse, len(response.Versions)),
+		Overrides: make([]LimitOverrideResponse, len(response.Overrides)),
+	}
+
+	for i, version := range response.Versions {
+		apiResponse.Versions[i] = BudgetVersionResponse{
+			Period:    version.Period,
+			Limit:     version.Limit,
+			CreatedAt: version.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if version.EffectiveFrom != nil {
+			effectiveFrom := version.EffectiveFrom.Format("2006-01-02")
+			apiResponse.Versions[i].EffectiveFrom = &effectiveFrom
+		}
+	}
+
+	for i, override := range response.Overrides {
+		apiResponse.Overrides[i] = toLimitOverrideResponse(override)
+	}
+
+	json.NewEncoder(w).Encode(apiResponse)
+}
+
+func (h *Handler) CreateLimitOverride(w http.ResponseWriter, r *http.Request) {
+	category, ok := budgetCategory(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateLimitOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	domainReq := domain.CreateLimitOverrideRequest{
+		Category: category,
+		Kind:     req.Kind,
+		Amount:   req.Amount,
+		Reason:   req.Reason,
+	}
+
+	startsOn, err := time.Parse("2006-01-02", req.StartsOn)
+	if err != nil {
+		http.Error(w, `{"error":"invalid starts_on date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+	domainReq.StartsOn = startsOn
+
+	endsOn, err := time.Parse("2006-01-02", req.EndsOn)
+	if err != nil {
+		http.Error(w, `{"error":"invalid ends_on date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+	domainReq.EndsOn = endsOn
+
+	response, err := h.ledgerService.CreateLimitOverride(r.Context(), domainReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrBudgetNotFound) {
+			http.Error(w, `{"error":"budget not found"}`, http.StatusNotFound)
+			return
+		}
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toLimitOverrideResponse(*response))
+}
+
+// budgetCategory возвращает категорию бюджета из параметра category. В пути вложенная
+// категория ("Транспорт/Такси") была бы неотличима от /status, /history и /suggestions.
+func budgetCategory(w http.ResponseWriter, r *http.Request) (string, bool) {
+	category := r.URL.Query().Get("category")
+	if strings.TrimSpace(category) == "" {
+		http.Error(w, `{"error":"category is required"}`, http.StatusBadRequest)
+		return "", false
+	}
+	return category, true
+}
+
+func toLimitOverrideResponse(response domain.LimitOverrideResponse) LimitOverrideResponse {
+	return LimitOverrideResponse{
+		ID:        response.ID,
+		Period:    response.Period,
+		Kind:      response.Kind,
+		Amount:    response.Amount,
+		StartsOn:  response.StartsOn.Format("2006-01-02"),
+		EndsOn:    response.EndsOn.Format("2006-01-02"),
+		Reason:    response.Reason,
+		Active:    response.Active,
+		CreatedAt: response.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func (h *Handler) GetBudgetSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	query := r.URL.Query()
+	req := BudgetSuggestionsRequest{
+		Period:     query.Get("period"),
+		Categories: query["category"],
+		Date:       query.Get("date"),
+	}
+
+	if periodsStr := query.Get("periods"); periodsStr != "" {
+		periods, err := strconv.Atoi(periodsStr)
+		if err != nil {
+			http.Error(w, `{"error":"periods must be an integer"}`, http.StatusBadRequest)
+			return
+		}
+		req.Periods = periods
+	}
+
+	if percentileStr := query.Get("percentile"); percentileStr != "" {
+		percentile, err := strconv.ParseFloat(percentileStr, 64)
+		if err != nil {
+			http.Error(w, `{"error":"percentile must be a number"}`, http.StatusBadRequest)
+			return
+		}
+		req.Percentile = percentile
+	}
+
+	domainReq, ok := toBudgetSuggestionsRequest(w, req)
+	if !ok {
+		return
+	}
+
+	responses, err := h.ledgerService.GetBudgetSuggestions(r.Context(), domainReq)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]BudgetSuggestionResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = BudgetSuggestionResponse{
+			Category:   response.Category,
+			Period:     response.Period,
+			Limit:      response.Limit,
+			Percentile: response.Percentile,
+			Basis:      toPeriodAmountResponses(response.Basis),
+			Outliers:   toPeriodAmountResponses(response.Outliers),
+		}
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func (h *Handler) ApplyBudgetSuggestions(w http.ResponseWriter, r *http.Request) {
+	var req BudgetSuggestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	domainReq, ok := toBudgetSuggestionsRequest(w, req)
+	if !ok {
+		return
+	}
+
+	responses, err := h.ledgerService.ApplyBudgetSuggestions(r.Context(), domainReq)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]BudgetResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = toBudgetResponse(response)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func toBudgetSuggestionsRequest(w http.ResponseWriter, req BudgetSuggestionsRequest) (domain.GetBudgetSuggestionsRequest, bool) {
+	domainReq := domain.GetBudgetSuggestionsRequest{
+		Period:     req.Period,
+		Periods:    req.Periods,
+		Percentile: req.Percentile,
+		Categories: req.Categories,
+	}
+
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, `{"error":"invalid date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+			return domainReq, false
+		}
+		domainReq.Date = date
+	}
+
+	return domainReq, true
+}
+
+func toPeriodAmountResponses(amounts []domain.PeriodAmountResponse) []PeriodAmountResponse {
+	apiResponses := make([]PeriodAmountResponse, len(amounts))
+	for i, amount := range amounts {
+		apiResponses[i] = PeriodAmountResponse{
+			PeriodStart: amount.PeriodStart.Format("2006-01-02"),
+			Amount:      amount.Amount,
+		}
+	}
+	return apiResponses
+}
+
+func (h *Handler) handleBudgetLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrBudgetNotFound) {
+		http.Error(w, `{"error":"budget not found"}`, http.StatusNotFound)
+		return
+	}
+	http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+}
+
+func toBudgetResponse(response domain.BudgetResponse) BudgetResponse {
+	apiResponse := BudgetResponse{
+		Category:          response.Category,
+		Limit:             response.Limit,
+		EffectiveLimit:    response.EffectiveLimit,
+		Currency:          response.Currency,
+		Period:            response.Period,
+		Rollover:          response.Rollover,
+		WarningThresholds: response.WarningThresholds,
+		OverspendPolicy:   response.OverspendPolicy,
+	}
+
+	if response.Status != nil {
+		status := toBudgetStatusResponse(*response.Status)
+		apiResponse.Status = &status
+	}
+
+	return apiResponse
+}
+
+func toBudgetStatusResponse(response domain.BudgetStatusResponse) BudgetStatusResponse {
+	return BudgetStatusResponse{
+		Category:    response.Category,
+		Period:      response.Period,
+		Limit:       response.Limit,
+		Spent:       response.Spent,
+		Remaining:   response.Remaining,
+		Currency:    response.Currency,
+		PercentUsed: response.PercentUsed,
+		PeriodStart: response.PeriodStart.Format("2006-01-02"),
+		PeriodEnd:   response.PeriodEnd.Format("2006-01-02"),
+		DaysLeft:    response.DaysLeft,
+	}
+}
+
+func (h *Handler) CreateBudgetPool(w http.ResponseWriter, r *http.Request) {
+	var req CreateBudgetPoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	domainReq := domain.CreateBudgetPoolRequest{
+		Name:       req.Name,
+		Categories: req.Categories,
+		Limit:      req.Limit,
+		Period:     req.Period,
+	}
+
+	response, err := h.ledgerService.CreateBudgetPool(r.Context(), domainReq)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toBudgetPoolResponse(*response))
+}
+
+func (h *Handler) ListBudgetPools(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	responses, err := h.ledgerService.ListBudgetPools(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	apiResponses := make([]BudgetPoolResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = toBudgetPoolResponse(response)
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func toBudgetPoolResponse(response domain.BudgetPoolResponse) BudgetPoolResponse {
+	return BudgetPoolResponse{
+		Name:       response.Name,
+		Categories: response.Categories,
+		Limit:      response.Limit,
+		Period:     response.Period,
+	}
+}
+
+func (h *Handler) Ping(w http.ResponseWriter, r *http.Request) {
+	// Проверяем, не отменен ли уже контекст
+	if r.Context().Err() != nil {
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "pong"})
+}
+
+func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	if err := h.ledgerService.HealthCheck(r.Context()); err != nil {
+		http.Error(w, `{"error":"service unavailable"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+}
+
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	errorMsg := err.Error()
+
+	var poolErr *domain.PoolExceededError
+
+	switch {
+	case errorMsg == "budget not found":
+		http.Error(w, `{"error":"budget not found"}`, http.StatusBadRequest)
+	case errors.As(err, &poolErr):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "budget pool exceeded",
+			"pool":  poolErr.Pool,
+		})
+	case errors.Is(err, domain.ErrBudgetExceeded):
+		http.Error(w, `{"error":"budget exceeded"}`, http.StatusConflict)
+	case errors.Is(err, domain.ErrOverrideRequired):
+		http.Error(w, `{"error":"budget override required"}`, http.StatusConflict)
+	case errors.Is(err, domain.ErrExchangeRateNotFound):
+		http.Error(w, `{"error":"exchange rate not found"}`, http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrValidation):
+		writeError(w, http.StatusBadRequest, errorMsg)
+	default:
+		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+	}
+}
+
+// writeError отдаёт ошибку JSON-объектом; текст ошибки может содержать кавычки,
+// поэтому он кодируется, а не подставляется в строку.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func (h *Handler) TimeoutTest(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(10 * time.Second)
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "completed after 10s"})
+}
+
+func (h *Handler) GetSpendingSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+
+	if fromStr == "" || toStr == "" {
+		http.Error(w, `{"error":"both from and to parameters are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	to = to.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	req := domain.GetSpendingSummaryRequest{
+		From:     from,
+		To:       to,
+		View:     r.URL.Query().Get("view"),
+		Currency: r.URL.Query().Get("currency"),
+		Tags:     parseTags(r.URL.Query().Get("tags")),
+	}
+
+	if req.View != "" {
+		breakdown, err := h.ledgerService.GetSpendingBreakdown(r.Context(), req)
+		if err != nil {
+			h.handleReportServiceError(w, err)
+			return
+		}
+
+		apiResponse := make([]CategorySpendingResponse, len(breakdown))
+		for i, node := range breakdown {
+			apiResponse[i] = toCategorySpendingResponse(node)
+		}
+
+		json.NewEncoder(w).Encode(apiResponse)
+		return
+	}
+
+	summary, err := h.ledgerService.GetSpendingSummary(r.Context(), req)
+	if err != nil {
+		h.handleReportServiceError(w, err)
+		return
+	}
+
+	if summary == nil {
+		summary = make(domain.SpendingSummary)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func toCategorySpendingResponse(node domain.CategorySpending) CategorySpendingResponse {
+	apiResponse := CategorySpendingResponse{
+		Category: node.Category,
+		Parent:   node.Parent,
+		Own:      node.Own,
+		Total:    node.Total,
+	}
+
+	for _, child := range node.Children {
+		apiResponse.Children = append(apiResponse.Children, toCategorySpendingResponse(child))
+	}
+
+	return apiResponse
+}
+
+func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	responses, err := h.ledgerService.ListCategories(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	apiResponses := make([]CategoryResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = CategoryResponse{
+			Name:   response.Name,
+			Parent: response.Parent,
+		}
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func (h *Handler) GetBudgetForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	var req domain.GetBudgetForecastRequest
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, `{"error":"invalid date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+			return
+		}
+		req.Date = date
+	}
+
+	responses, err := h.ledgerService.GetBudgetForecast(r.Context(), req)
+	if err != nil {
+		h.handleReportServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]BudgetForecastResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = BudgetForecastResponse{
+			Category:       response.Category,
+			Period:         response.Period,
+			Limit:          response.Limit,
+			Spent:          response.Spent,
+			PeriodStart:    response.PeriodStart.Format("2006-01-02"),
+			PeriodEnd:      response.PeriodEnd.Format("2006-01-02"),
+			ElapsedDays:    response.ElapsedDays,
+			TotalDays:      response.TotalDays,
+			DailyVelocity:  response.DailyVelocity,
+			IdealSpent:     response.IdealSpent,
+			ProjectedTotal: response.ProjectedTotal,
+			Status:         response.Status,
+		}
+		if response.ProjectedExhaustion != nil {
+			exhaustion := response.ProjectedExhaustion.Format("2006-01-02")
+			apiResponses[i].ProjectedExhaustion = &exhaustion
+		}
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func (h *Handler) GetOverspendReport(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+
+	if fromStr == "" || toStr == "" {
+		http.Error(w, `{"error":"both from and to parameters are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	req := domain.GetOverspendReportRequest{
+		From: from,
+		To:   to.Add(23*time.Hour + 59*time.Minute + 59*time.Second),
+	}
+
+	responses, err := h.ledgerService.GetOverspendReport(r.Context(), req)
+	if err != nil {
+		h.handleReportServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]OverspendResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = OverspendResponse{
+			Transaction:    toTransactionResponse(response.Transaction),
+			BudgetCategory: response.BudgetCategory,
+			Policy:         response.Policy,
+			Reason:         response.Reason,
+			Limit:          response.Limit,
+			Spent:          response.Spent,
+			CreatedAt:      response.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func (h *Handler) GetTagSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+
+	if fromStr == "" || toStr == "" {
+		http.Error(w, `{"error":"both from and to parameters are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	req := domain.GetTagSummaryRequest{
+		From:     from,
+		To:       to.Add(23*time.Hour + 59*time.Minute + 59*time.Second),
+		Currency: r.URL.Query().Get("currency"),
+		Tags:     parseTags(r.URL.Query().Get("tags")),
+	}
+
+	responses, err := h.ledgerService.GetTagSummary(r.Context(), req)
+	if err != nil {
+		h.handleReportServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]TagSpendingResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = TagSpendingResponse{
+			Tag:          response.Tag,
+			Amount:       response.Amount,
+			Transactions: response.Transactions,
+		}
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func (h *Handler) GetCashFlow(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+
+	if fromStr == "" || toStr == "" {
+		http.Error(w, `{"error":"both from and to parameters are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid from date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		http.Error(w, `{"error":"invalid to date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	req := domain.GetCashFlowRequest{
+		From:     from,
+		To:       to.Add(23*time.Hour + 59*time.Minute + 59*time.Second),
+		Currency: r.URL.Query().Get("currency"),
+	}
+
+	response, err := h.ledgerService.GetCashFlow(r.Context(), req)
+	if err != nil {
+		h.handleReportServiceError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(CashFlowResponse{
+		Income:      response.Income,
+		Expenses:    response.Expenses,
+		Refunds:     response.Refunds,
+		NetSpending: response.NetSpending,
+		Net:         response.Net,
+		Currency:    response.Currency,
+	})
+}
+
+func (h *Handler) CreateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var req CreateExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		http.Error(w, `{"error":"invalid date format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.ledgerService.CreateExchangeRate(r.Context(), domain.CreateExchangeRateRequest{
+		Currency:     req.Currency,
+		BaseCurrency: req.BaseCurrency,
+		Date:         date,
+		Rate:         req.Rate,
+	})
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toExchangeRateResponse(*response))
+}
+
+func (h *Handler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	responses, err := h.ledgerService.ListExchangeRates(r.Context(), domain.ListExchangeRatesRequest{
+		Currency:     r.URL.Query().Get("currency"),
+		BaseCurrency: r.URL.Query().Get("base_currency"),
+	})
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]ExchangeRateResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = toExchangeRateResponse(response)
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func toExchangeRateResponse(response domain.ExchangeRateResponse) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		Currency:     response.Currency,
+		BaseCurrency: response.BaseCurrency,
+		Date:         response.Date.Format("2006-01-02"),
+		Rate:         response.Rate,
+	}
+}
+
+func (h *Handler) CreateRecurring(w http.ResponseWriter, r *http.Request) {
+	var req CreateRecurringRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	startsOn, err := time.Parse("2006-01-02", req.StartsOn)
+	if err != nil {
+		http.Error(w, `{"error":"invalid starts_on format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+		return
+	}
+
+	domainReq := domain.CreateRecurringRequest{
+		Type:        req.Type,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Category:    req.Category,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Frequency:   req.Frequency,
+		Interval:    req.Interval,
+		DayOfMonth:  req.DayOfMonth,
+		StartsOn:    startsOn,
+	}
+
+	if req.EndsOn != "" {
+		endsOn, err := time.Parse("2006-01-02", req.EndsOn)
+		if err != nil {
+			http.Error(w, `{"error":"invalid ends_on format, expected YYYY-MM-DD"}`, http.StatusBadRequest)
+			return
+		}
+		domainReq.EndsOn = &endsOn
+	}
+
+	response, err := h.ledgerService.CreateRecurring(r.Context(), domainReq)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toRecurringResponse(*response))
+}
+
+func (h *Handler) ListRecurring(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	responses, err := h.ledgerService.ListRecurring(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]RecurringResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = toRecurringResponse(response)
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+// DeleteRecurring останавливает проведение; уже созданные транзакции и история проведений остаются.
+func (h *Handler) DeleteRecurring(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if err := h.ledgerService.DeleteRecurring(r.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrRecurringNotFound) {
+			http.Error(w, `{"error":"recurring transaction not found"}`, http.StatusNotFound)
+			return
+		}
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListOccurrences отдаёт проведения одной повторяющейся транзакции или всех сразу;
+// status=rejected показывает проведения, отклонённые бюджетом.
+func (h *Handler) ListOccurrences(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Err() != nil {
+		return
+	}
+
+	req := domain.ListOccurrencesRequest{Status: r.URL.Query().Get("status")}
+	if id, ok := mux.Vars(r)["id"]; ok {
+		req.RecurringID, _ = strconv.Atoi(id)
+	}
+
+	responses, err := h.ledgerService.ListOccurrences(r.Context(), req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	apiResponses := make([]OccurrenceResponse, len(responses))
+	for i, response := range responses {
+		apiResponses[i] = OccurrenceResponse{
+			ID:            response.ID,
+			RecurringID:   response.RecurringID,
+			DueOn:         response.DueOn.Format("2006-01-02"),
+			Status:        response.Status,
+			TransactionID: response.TransactionID,
+			Error:         response.Error,
+			CreatedAt:     response.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	json.NewEncoder(w).Encode(apiResponses)
+}
+
+func toRecurringResponse(response domain.RecurringResponse) RecurringResponse {
+	apiResponse := RecurringResponse{
+		ID:          response.ID,
+		Type:        response.Type,
+		Amount:      response.Amount,
+		Currency:    response.Currency,
+		Category:    response.Category,
+		Description: response.Description,
+		Tags:        response.Tags,
+		Frequency:   response.Frequency,
+		Interval:    response.Interval,
+		DayOfMonth:  response.DayOfMonth,
+		StartsOn:    response.StartsOn.Format("2006-01-02"),
+		Active:      response.Active,
+	}
+	if response.EndsOn != nil {
+		apiResponse.EndsOn = response.EndsOn.Format("2006-01-02")
+	}
+	return apiResponse
 }
 
 func (h *Handler) handleReportServiceError(w http.ResponseWriter, err error) {
@@ -242,8 +1556,13 @@ func (h *Handler) handleReportServiceError(w http.ResponseWriter, err error) {
 	switch {
 	case strings.Contains(errorMsg, "dates are required"),
 		strings.Contains(errorMsg, "from date cannot be after to date"),
-		strings.Contains(errorMsg, "period cannot exceed"):
-		http.Error(w, `{"error":"`+errorMsg+`"}`, http.StatusBadRequest)
+		strings.Contains(errorMsg, "period cannot exceed"),
+		strings.Contains(errorMsg, "view must be"),
+		strings.Contains(errorMsg, "currency must be"),
+		strings.Contains(errorMsg, "tag cannot"):
+		writeError(w, http.StatusBadRequest, errorMsg)
+	case errors.Is(err, domain.ErrExchangeRateNotFound):
+		http.Error(w, `{"error":"exchange rate not found"}`, http.StatusUnprocessableEntity)
 	default:
 		http.Error(w, `{"error":"Internal error"}`, http.StatusInternalServerError)
 	}
@@ -282,9 +1601,14 @@ func (h *Handler) CreateTransactionsBulk(w http.ResponseWriter, r *http.Request)
 
 	for i, tx := range req.Transactions {
 		domainReq := domain.CreateTransactionRequest{
-			Amount:      tx.Amount,
-			Category:    tx.Category,
-			Description: tx.Description,
+			Type:           tx.Type,
+			Amount:         tx.Amount,
+			Currency:       tx.Currency,
+			Category:       tx.Category,
+			Description:    tx.Description,
+			Tags:           tx.Tags,
+			Override:       tx.Override,
+			OverrideReason: tx.OverrideReason,
 		}
 
 		if tx.Date != "" {
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"ledger/domain"
+	"log"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotentRequestBytes = 10 << 20
+)
+
+// storedHeaders — заголовки ответа, которые повторяются вместе с телом.
+var storedHeaders = []string{"Content-Type", "Location", budgetWarningHeader}
+
+// Idempotent выполняет запрос с заголовком Idempotency-Key один раз: повтор с тем же ключом
+// и телом получает сохранённый ответ, с другим телом — 422. Ответы 5xx не сохраняются,
+// такой запрос можно повторить с тем же ключом.
+func (h *Handler) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, `{"error":"request body is too large"}`, http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, `{"error":"failed to read request body"}`, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := h.ledgerService.BeginIdempotent(r.Context(), domain.IdempotentRequest{
+			Key:    key,
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Body:   body,
+		})
+		if err != nil {
+			h.handleIdempotencyError(w, err)
+			return
+		}
+		if stored != nil {
+			replayResponse(w, *stored)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next(recorder, r)
+
+		// Решение принимается только по записанному статусу: отмена запроса клиентом не отменяет
+		// уже выполненную операцию, поэтому её ответ сохраняется в контексте без отмены.
+		// Ключ освобождается, только если ответа нет или это 5xx.
+		ctx := context.WithoutCancel(r.Context())
+		if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+			if err := h.ledgerService.ReleaseIdempotent(ctx, key); err != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, err)
+			}
+			return
+		}
+
+		response := domain.StoredResponse{
+			StatusCode: recorder.status,
+			Header:     make(map[string][]string),
+			Body:       recorder.body.Bytes(),
+		}
+		for _, name := range storedHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				response.Header[name] = values
+			}
+		}
+		if err := h.ledgerService.CompleteIdempotent(ctx, key, response); err != nil {
+			log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+func replayResponse(w http.ResponseWriter, response domain.StoredResponse) {
+	for name, values := range response.Header {
+		w.Header().Del(name)
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+}
+
+func (h *Handler) handleIdempotencyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrIdempotencyKeyMismatch):
+		http.Error(w, `{"error":"idempotency key was already used with a different request"}`, http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+		http.Error(w, `{"error":"request with this idempotency key is still in progress"}`, http.StatusConflict)
+	default:
+		h.handleServiceError(w, err)
+	}
+}
+
+// responseRecorder передаёт ответ клиенту и запоминает статус и тело.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
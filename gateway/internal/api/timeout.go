@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"time"
 )
@@ -14,3 +16,26 @@ func TimeoutMiddlewareWithDuration(timeout time.Duration) func(http.Handler) htt
 		return http.TimeoutHandler(next, timeout, `{"error":"Request timeout"}`)
 	}
 }
+
+// TransferMiddleware для загрузки и скачивания файлов: вместо TimeoutMiddleware, который
+// буферизует весь ответ, продлевает сроки чтения запроса и записи ответа сервера до timeout.
+func TransferMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline := time.Now().Add(timeout)
+
+			controller := http.NewResponseController(w)
+			if err := controller.SetReadDeadline(deadline); err != nil {
+				log.Printf("Failed to extend read deadline: %v", err)
+			}
+			if err := controller.SetWriteDeadline(deadline); err != nil {
+				log.Printf("Failed to extend write deadline: %v", err)
+			}
+
+			ctx, cancel := context.WithDeadline(r.Context(), deadline)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
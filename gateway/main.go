@@ -37,21 +37,66 @@ func main() {
 func setupRouter(handler *api.Handler) *mux.Router {
 	r := mux.NewRouter()
 
+	// Файлы передаются дольше 2 секунд, поэтому у вложений свой роутер без TimeoutMiddleware.
+	// Он регистрируется раньше /api, чтобы его маршруты проверялись первыми.
+	attachmentRouter := r.PathPrefix("/api/transactions/{id:[0-9]+}/attachments").Subrouter()
+
+	attachmentRouter.Use(api.TransferMiddleware(5 * time.Minute))
+	attachmentRouter.Use(api.JSONMiddleware)
+	attachmentRouter.Use(api.LoggingMiddleware)
+
+	attachmentRouter.HandleFunc("", handler.UploadAttachment).Methods("POST")
+	attachmentRouter.HandleFunc("", handler.ListAttachments).Methods("GET")
+	attachmentRouter.HandleFunc("/{attachmentID:[0-9]+}", handler.DownloadAttachment).Methods("GET")
+	attachmentRouter.HandleFunc("/{attachmentID:[0-9]+}", handler.DeleteAttachment).Methods("DELETE")
+
 	apiRouter := r.PathPrefix("/api").Subrouter()
 
 	apiRouter.Use(api.TimeoutMiddleware()) // Таймаут 2 секунды (первым!)
 	apiRouter.Use(api.JSONMiddleware)      // JSON responses
 	apiRouter.Use(api.LoggingMiddleware)   // Логирование
 
-	apiRouter.HandleFunc("/transactions", handler.CreateTransactionHandler).Methods("POST")
+	apiRouter.HandleFunc("/transactions", handler.Idempotent(handler.CreateTransactionHandler)).Methods("POST")
 	apiRouter.HandleFunc("/transactions", handler.ListTransactions).Methods("GET")
+	apiRouter.HandleFunc("/transactions/search", handler.SearchTransactions).Methods("GET")
+	apiRouter.HandleFunc("/transactions/{id:[0-9]+}", handler.GetTransaction).Methods("GET")
+	apiRouter.HandleFunc("/transactions/{id:[0-9]+}", handler.ReplaceTransaction).Methods("PUT")
+	apiRouter.HandleFunc("/transactions/{id:[0-9]+}", handler.PatchTransaction).Methods("PATCH")
+	apiRouter.HandleFunc("/transactions/{id:[0-9]+}", handler.DeleteTransaction).Methods("DELETE")
+	apiRouter.HandleFunc("/transactions/{id:[0-9]+}/history", handler.GetTransactionHistory).Methods("GET")
+	apiRouter.HandleFunc("/transactions/splits", handler.Idempotent(handler.CreateSplit)).Methods("POST")
+	apiRouter.HandleFunc("/transactions/splits/{id:[0-9]+}", handler.GetSplit).Methods("GET")
+	apiRouter.HandleFunc("/transactions/splits/{id:[0-9]+}", handler.DeleteSplit).Methods("DELETE")
 	apiRouter.HandleFunc("/budgets", handler.CreateBudget).Methods("POST")
+	// Категории бывают вложенными ("Транспорт/Такси") и в пути были бы неотличимы от status,
+	// history и suggestions, поэтому бюджет выбирается параметром category
+	apiRouter.HandleFunc("/budgets", handler.GetBudget).Methods("GET").Queries("category", "{category}")
 	apiRouter.HandleFunc("/budgets", handler.ListBudgets).Methods("GET")
+	apiRouter.HandleFunc("/budgets/status", handler.GetBudgetStatus).Methods("GET")
+	apiRouter.HandleFunc("/budgets/history", handler.GetBudgetHistory).Methods("GET")
+	apiRouter.HandleFunc("/budgets/overrides", handler.CreateLimitOverride).Methods("POST")
+	apiRouter.HandleFunc("/budgets/suggestions", handler.GetBudgetSuggestions).Methods("GET")
+	apiRouter.HandleFunc("/budgets/suggestions", handler.ApplyBudgetSuggestions).Methods("POST")
+	apiRouter.HandleFunc("/categories", handler.ListCategories).Methods("GET")
+	apiRouter.HandleFunc("/categories/{category:.+}/transactions", handler.ListTransactions).Methods("GET")
+	apiRouter.HandleFunc("/exchange-rates", handler.CreateExchangeRate).Methods("POST")
+	apiRouter.HandleFunc("/exchange-rates", handler.ListExchangeRates).Methods("GET")
+	apiRouter.HandleFunc("/recurring", handler.CreateRecurring).Methods("POST")
+	apiRouter.HandleFunc("/recurring", handler.ListRecurring).Methods("GET")
+	apiRouter.HandleFunc("/recurring/occurrences", handler.ListOccurrences).Methods("GET")
+	apiRouter.HandleFunc("/recurring/{id:[0-9]+}", handler.DeleteRecurring).Methods("DELETE")
+	apiRouter.HandleFunc("/recurring/{id:[0-9]+}/occurrences", handler.ListOccurrences).Methods("GET")
+	apiRouter.HandleFunc("/budget-pools", handler.CreateBudgetPool).Methods("POST")
+	apiRouter.HandleFunc("/budget-pools", handler.ListBudgetPools).Methods("GET")
 	apiRouter.HandleFunc("/ping", handler.Ping).Methods("GET")
 	apiRouter.HandleFunc("/health", handler.HealthCheck).Methods("GET")
 	apiRouter.HandleFunc("/timeout-test", handler.TimeoutTest).Methods("GET")
 	apiRouter.HandleFunc("/reports/summary", handler.GetSpendingSummary).Methods("GET")
-	apiRouter.HandleFunc("/transactions/bulk", handler.CreateTransactionsBulk).Methods("POST")
+	apiRouter.HandleFunc("/reports/forecast", handler.GetBudgetForecast).Methods("GET")
+	apiRouter.HandleFunc("/reports/over-budget", handler.GetOverspendReport).Methods("GET")
+	apiRouter.HandleFunc("/reports/cashflow", handler.GetCashFlow).Methods("GET")
+	apiRouter.HandleFunc("/reports/tags", handler.GetTagSummary).Methods("GET")
+	apiRouter.HandleFunc("/transactions/bulk", handler.Idempotent(handler.CreateTransactionsBulk)).Methods("POST")
 
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
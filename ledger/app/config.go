@@ -14,6 +14,13 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 	DBTimeout  time.Duration
+
+	// RecurringInterval — как часто планировщик проводит повторяющиеся транзакции.
+	RecurringInterval time.Duration
+	// AttachmentsDir — каталог хранилища вложений.
+	AttachmentsDir string
+	// IdempotencyTTL — сколько хранятся ключи идемпотентности и ответы на запросы с ними.
+	IdempotencyTTL time.Duration
 }
 
 func LoadConfig() *Config {
@@ -25,6 +32,10 @@ func LoadConfig() *Config {
 		DBName:     getEnv("DB_NAME", "postgres"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 		DBTimeout:  getEnvAsDuration("DB_TIMEOUT", 5*time.Second),
+
+		RecurringInterval: getEnvAsDuration("RECURRING_INTERVAL", time.Minute),
+		AttachmentsDir:    getEnv("ATTACHMENTS_DIR", "data/attachments"),
+		IdempotencyTTL:    getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
 	}
 }
 
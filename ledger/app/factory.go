@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"ledger/repository/fs"
 	pg2 "ledger/repository/pg"
 	service2 "ledger/service"
 	"time"
@@ -33,10 +34,32 @@ func New(ctx context.Context) (*App, error) {
 
 	transactionRepo := pg2.NewTransactionRepository(db)
 	budgetRepo := pg2.NewBudgetRepository(db)
+	categoryRepo := pg2.NewCategoryRepository(db)
+	poolRepo := pg2.NewBudgetPoolRepository(db)
+	overspendRepo := pg2.NewOverspendRepository(db)
+	rateRepo := pg2.NewExchangeRateRepository(db)
+	recurringRepo := pg2.NewRecurringRepository(db)
+	attachmentRepo := pg2.NewAttachmentRepository(db)
+	idempotencyRepo := pg2.NewIdempotencyRepository(db, config.IdempotencyTTL)
+	transactor := pg2.NewTransactor(db)
+
+	blobStore, err := fs.NewBlobStore(config.AttachmentsDir)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize attachment storage: %w", err)
+	}
+
+	ledgerService := service2.NewLedgerService(
+		transactionRepo, budgetRepo, categoryRepo, poolRepo, overspendRepo, rateRepo, recurringRepo,
+		attachmentRepo, blobStore, idempotencyRepo, transactor,
+	)
 
-	ledgerService := service2.NewLedgerService(transactionRepo, budgetRepo)
+	// Планировщик живёт до Close, а не до ctx инициализации
+	scheduler := service2.NewRecurringScheduler(ledgerService, config.RecurringInterval)
+	scheduler.Start()
 
 	closeFn := func() error {
+		scheduler.Stop()
 		if err := db.Close(); err != nil {
 			return fmt.Errorf("failed to close database: %w", err)
 		}
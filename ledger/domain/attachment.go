@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxFilenameLength — наибольшая длина имени файла вложения в символах.
+const MaxFilenameLength = 255
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrBlobNotFound       = errors.New("blob not found")
+)
+
+// Attachment — файл, приложенный к транзакции (фото чека, PDF). Содержимое хранится
+// в BlobStore по SHA256, поэтому одинаковые файлы занимают место один раз.
+type Attachment struct {
+	ID            int
+	TransactionID int
+	Filename      string
+	ContentType   string
+	Size          int64
+	SHA256        string
+	CreatedAt     time.Time
+}
+
+// SanitizeFilename оставляет от имени файла только последний элемент пути без управляющих
+// символов и обрезает его до MaxFilenameLength.
+func SanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	name = filepath.Base("/" + name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "/" || name == "." || name == ".." || name == "" {
+		return "attachment"
+	}
+	for utf8.RuneCountInString(name) > MaxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
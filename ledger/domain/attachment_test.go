@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "чек.pdf", want: "чек.pdf"},
+		{name: "unix path", in: "../../etc/passwd", want: "passwd"},
+		{name: "windows path", in: `C:\Users\me\receipt.jpg`, want: "receipt.jpg"},
+		{name: "control characters", in: "re\r\nceipt\".png", want: "receipt.png"},
+		{name: "empty", in: "", want: "attachment"},
+		{name: "dots", in: "..", want: "attachment"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := SanitizeFilename(tc.in); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilenameTruncates(t *testing.T) {
+	t.Parallel()
+
+	got := SanitizeFilename(strings.Repeat("я", MaxFilenameLength+10))
+	if utf8.RuneCountInString(got) != MaxFilenameLength {
+		t.Errorf("Expected %d characters, got %d", MaxFilenameLength, utf8.RuneCountInString(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("Expected valid UTF-8, got %q", got)
+	}
+}
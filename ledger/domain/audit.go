@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Действия в журнале изменений транзакций.
+const (
+	ChangeCreate  = "create"
+	ChangeUpdate  = "update"
+	ChangeDelete  = "delete"
+	ChangeReverse = "reverse"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrTransactionReversed = errors.New("transaction is reversed")
+)
+
+// Режимы удаления транзакции.
+const (
+	DeleteModeSoft     = "soft"     // пометить удалённой, из отчётов она исчезает
+	DeleteModeReversal = "reversal" // оставить и добавить сторнирующую запись с обратной суммой
+)
+
+// TransactionSnapshot — значения полей транзакции до или после изменения.
+type TransactionSnapshot struct {
+	Type        string    `json:"type,omitempty"`
+	Amount      Money     `json:"amount"`
+	Currency    string    `json:"currency,omitempty"`
+	Category    string    `json:"category"`
+	Description string    `json:"description"`
+	Date        time.Time `json:"date"`
+	Tags        []string  `json:"tags,omitempty"`
+}
+
+func SnapshotOf(transaction Transaction) *TransactionSnapshot {
+	return &TransactionSnapshot{
+		Type:        transaction.Type,
+		Amount:      transaction.Amount,
+		Currency:    transaction.Currency,
+		Category:    transaction.Category,
+		Description: transaction.Description,
+		Date:        transaction.Date,
+		Tags:        transaction.Tags,
+	}
+}
+
+// TransactionChange — запись журнала изменений транзакции.
+type TransactionChange struct {
+	ID            int
+	TransactionID int
+	Action        string
+	Before        *TransactionSnapshot
+	After         *TransactionSnapshot
+	CreatedAt     time.Time
+}
+
+// Reversal возвращает сторнирующую запись: та же категория и дата, обратная сумма.
+func (t Transaction) Reversal() Transaction {
+	reversesID := t.ID
+	return Transaction{
+		Type:        t.Type,
+		Amount:      -t.Amount,
+		Currency:    t.Currency,
+		Category:    t.Category,
+		Description: "Сторно: " + t.Description,
+		Date:        t.Date,
+		Tags:        t.Tags,
+		ReversesID:  &reversesID,
+	}
+}
+
+// Locked сообщает, что транзакцию нельзя менять: это сторнирующая запись или она уже сторнирована.
+func (t Transaction) Locked() bool {
+	return t.ReversesID != nil || t.ReversedByID != nil
+}
+
+// CountsToward сообщает, входит ли транзакция в расходы бюджета категории category
+// (с учётом потомков) за период с from по to. Доходы в расходы не входят.
+func (t Transaction) CountsToward(category string, from, to time.Time) bool {
+	if t.Type == TransactionIncome || t.Date.Before(from) || t.Date.After(to) {
+		return false
+	}
+	for _, name := range CategoryLineage(t.Category) {
+		if name == category {
+			return true
+		}
+	}
+	return false
+}
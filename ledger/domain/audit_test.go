@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransactionCountsToward(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	transaction := Transaction{
+		Amount:   500,
+		Category: "Транспорт/Такси",
+		Date:     time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name     string
+		category string
+		from, to time.Time
+		want     bool
+	}{
+		{name: "own category", category: "Транспорт/Такси", from: from, to: to, want: true},
+		{name: "parent category", category: "Транспорт", from: from, to: to, want: true},
+		{name: "sibling category", category: "Транспорт/Метро", from: from, to: to, want: false},
+		{name: "other period", category: "Транспорт", from: from.AddDate(0, 1, 0), to: to.AddDate(0, 1, 0), want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := transaction.CountsToward(tc.category, tc.from, tc.to); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTransactionReversal(t *testing.T) {
+	t.Parallel()
+
+	original := Transaction{
+		ID:          42,
+		Amount:      1500,
+		Category:    "Кафе",
+		Description: "Ужин",
+		Date:        time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC),
+	}
+
+	reversal := original.Reversal()
+
+	if reversal.Amount != -1500 {
+		t.Errorf("Expected amount -1500, got %v", reversal.Amount)
+	}
+	if reversal.Category != original.Category || !reversal.Date.Equal(original.Date) {
+		t.Errorf("Expected category and date of the original, got %q %v", reversal.Category, reversal.Date)
+	}
+	if reversal.ReversesID == nil || *reversal.ReversesID != 42 {
+		t.Errorf("Expected reversal of 42, got %v", reversal.ReversesID)
+	}
+	if !reversal.Locked() {
+		t.Errorf("Expected reversal entry to be locked")
+	}
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// CategorySeparator разделяет уровни иерархии в имени категории: "Транспорт/Такси".
+const CategorySeparator = "/"
+
+// Category — узел дерева категорий. Name хранит полный путь от корня.
+type Category struct {
+	ID       int
+	Name     string
+	ParentID *int
+}
+
+// ValidateCategory проверяет, что в пути категории нет пустых уровней.
+func ValidateCategory(name string) error {
+	for _, part := range strings.Split(name, CategorySeparator) {
+		if strings.TrimSpace(part) == "" {
+			return errors.New("category path contains an empty level")
+		}
+	}
+	return nil
+}
+
+// CategoryParent возвращает родителя категории или пустую строку для корневой.
+func CategoryParent(name string) string {
+	if i := strings.LastIndex(name, CategorySeparator); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// CategoryLineage возвращает категорию и всех её предков, начиная с самой категории.
+func CategoryLineage(name string) []string {
+	lineage := []string{name}
+	for parent := CategoryParent(name); parent != ""; parent = CategoryParent(parent) {
+		lineage = append(lineage, parent)
+	}
+	return lineage
+}
+
+// CategorySpending — расходы по узлу дерева категорий: Own — по самой категории,
+// Total — вместе со всеми потомками.
+type CategorySpending struct {
+	Category string             `json:"category"`
+	Parent   string             `json:"parent,omitempty"`
+	Own      Money              `json:"own"`
+	Total    Money              `json:"total"`
+	Children []CategorySpending `json:"children,omitempty"`
+}
+
+// BuildSpendingTree строит дерево расходов из сумм по отдельным категориям,
+// добавляя промежуточные уровни и подытоги. Узлы отсортированы по имени.
+func BuildSpendingTree(summary SpendingSummary) []CategorySpending {
+	nodes := make(map[string]*CategorySpending)
+	children := make(map[string][]string)
+
+	var ensure func(name string) *CategorySpending
+	ensure = func(name string) *CategorySpending {
+		if node, ok := nodes[name]; ok {
+			return node
+		}
+		node := &CategorySpending{Category: name, Parent: CategoryParent(name)}
+		nodes[name] = node
+		if node.Parent != "" {
+			ensure(node.Parent)
+		}
+		children[node.Parent] = append(children[node.Parent], name)
+		return node
+	}
+
+	for category, amount := range summary {
+		ensure(category).Own += amount
+		for _, name := range CategoryLineage(category) {
+			nodes[name].Total += amount
+		}
+	}
+
+	var build func(parent string) []CategorySpending
+	build = func(parent string) []CategorySpending {
+		names := children[parent]
+		sort.Strings(names)
+
+		result := make([]CategorySpending, 0, len(names))
+		for _, name := range names {
+			node := *nodes[name]
+			node.Children = build(name)
+			result = append(result, node)
+		}
+		return result
+	}
+
+	return build("")
+}
+
+// FlattenSpendingTree разворачивает дерево в список (родитель перед потомками) с подытогами.
+func FlattenSpendingTree(tree []CategorySpending) []CategorySpending {
+	var flat []CategorySpending
+	for _, node := range tree {
+		children := node.Children
+		node.Children = nil
+		flat = append(flat, node)
+		flat = append(flat, FlattenSpendingTree(children)...)
+	}
+	return flat
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCategoryLineage(t *testing.T) {
+	t.Parallel()
+
+	got := CategoryLineage("Транспорт/Такси/Аэропорт")
+	want := []string{"Транспорт/Такси/Аэропорт", "Транспорт/Такси", "Транспорт"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestValidateCategory(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"Транспорт", "Транспорт/Такси"} {
+		if err := ValidateCategory(name); err != nil {
+			t.Errorf("Expected %q to be valid, got '%s'", name, err)
+		}
+	}
+	for _, name := range []string{"/Такси", "Транспорт/", "Транспорт// Такси", "Транспорт/ /Такси"} {
+		if err := ValidateCategory(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestBuildSpendingTree(t *testing.T) {
+	t.Parallel()
+
+	summary := SpendingSummary{
+		"Транспорт":            100,
+		"Транспорт/Такси":      250,
+		"Транспорт/Метро":      50,
+		"Продукты/Супермаркет": 400,
+	}
+
+	tree := BuildSpendingTree(summary)
+
+	want := []CategorySpending{
+		{
+			Category: "Продукты",
+			Own:      0,
+			Total:    400,
+			Children: []CategorySpending{
+				{Category: "Продукты/Супермаркет", Parent: "Продукты", Own: 400, Total: 400, Children: []CategorySpending{}},
+			},
+		},
+		{
+			Category: "Транспорт",
+			Own:      100,
+			Total:    400,
+			Children: []CategorySpending{
+				{Category: "Транспорт/Метро", Parent: "Транспорт", Own: 50, Total: 50, Children: []CategorySpending{}},
+				{Category: "Транспорт/Такси", Parent: "Транспорт", Own: 250, Total: 250, Children: []CategorySpending{}},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(tree, want) {
+		t.Errorf("Expected %+v, got %+v", want, tree)
+	}
+
+	flat := FlattenSpendingTree(tree)
+	names := make([]string, len(flat))
+	for i, node := range flat {
+		names[i] = node.Category
+		if node.Children != nil {
+			t.Errorf("Expected flattened node %q to have no children", node.Category)
+		}
+	}
+
+	wantNames := []string{"Продукты", "Продукты/Супермаркет", "Транспорт", "Транспорт/Метро", "Транспорт/Такси"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Expected %v, got %v", wantNames, names)
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// BaseCurrency — валюта по умолчанию для транзакций, бюджетов и отчётов.
+// В ней же ведутся общие бюджеты.
+const BaseCurrency = "RUB"
+
+var (
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	// ErrBudgetCurrencyChanged — валюта существующего бюджета не меняется: в ней записаны
+	// прежние версии лимита и суммы превышений.
+	ErrBudgetCurrencyChanged = errors.New("budget currency cannot be changed")
+)
+
+// ExchangeRate — курс на дату Date: одна единица Currency стоит Rate единиц BaseCurrency.
+// Курс действует до даты следующего курса той же пары; обратная пара считается по нему же.
+type ExchangeRate struct {
+	Currency     string
+	BaseCurrency string
+	Date         time.Time
+	Rate         float64
+}
+
+func (r ExchangeRate) Validate() error {
+	if ValidateCurrency(r.Currency) != nil || ValidateCurrency(r.BaseCurrency) != nil {
+		return errors.New("код валюты должен состоять из трёх латинских букв")
+	}
+	if r.Currency == r.BaseCurrency {
+		return errors.New("валюты курса должны различаться")
+	}
+	if r.Rate <= 0 {
+		return errors.New("курс должен быть положительным числом")
+	}
+	if r.Date.IsZero() {
+		return errors.New("дата курса должна быть указана")
+	}
+	return nil
+}
+
+// NormalizeCurrency приводит код валюты к верхнему регистру; пустой код означает BaseCurrency.
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return BaseCurrency
+	}
+	return code
+}
+
+// ValidateCurrency проверяет код валюты ISO 4217: три латинские буквы; пустой означает BaseCurrency.
+func ValidateCurrency(code string) error {
+	code = NormalizeCurrency(code)
+	if len(code) != 3 {
+		return errors.New("currency must be a 3-letter ISO code")
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return errors.New("currency must be a 3-letter ISO code")
+		}
+	}
+	return nil
+}
+
+// Convert переводит сумму по точному курсу rate с округлением до копейки половины от нуля,
+// как ROUND(amount * rate, 2) в функции convert_amount в базе.
+func (m Money) Convert(rate *big.Rat) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), rate)
+
+	quotient, remainder := new(big.Int).QuoRem(product.Num(), product.Denom(), new(big.Int))
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(product.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(product.Sign())))
+	}
+
+	return Money(quotient.Int64())
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestValidateCurrency(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		code    string
+		wantErr bool
+	}{
+		{code: "", wantErr: false},
+		{code: "EUR", wantErr: false},
+		{code: "usd", wantErr: false},
+		{code: "RU", wantErr: true},
+		{code: "EURO", wantErr: true},
+		{code: "E1R", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.code, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateCurrency(tc.code)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	t.Parallel()
+
+	if got := NormalizeCurrency(""); got != BaseCurrency {
+		t.Errorf("Expected %s, got %s", BaseCurrency, got)
+	}
+	if got := NormalizeCurrency(" eur "); got != "EUR" {
+		t.Errorf("Expected EUR, got %s", got)
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		amount Money
+		rate   *big.Rat
+		want   Money
+	}{
+		{name: "whole rate", amount: 1000, rate: mustRate("100"), want: 100000},
+		{name: "fractional rate", amount: 1050, rate: mustRate("98.7654"), want: 103704},
+		{name: "inverse rate", amount: 50000, rate: new(big.Rat).Inv(mustRate("98.7654")), want: 506},
+		{name: "refund", amount: -1050, rate: mustRate("98.7654"), want: -103704},
+		{name: "same currency", amount: 12345, rate: mustRate("1"), want: 12345},
+		{name: "half cent rounds up", amount: 50, rate: mustRate("1.15"), want: 58},
+		{name: "half cent refund rounds away from zero", amount: -50, rate: mustRate("1.15"), want: -58},
+		{name: "below half cent rounds down", amount: 10, rate: mustRate("1.04"), want: 10},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.amount.Convert(tc.rate); got != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func mustRate(value string) *big.Rat {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		panic("invalid rate " + value)
+	}
+	return rate
+}
+
+func TestExchangeRateValidate(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		rate    ExchangeRate
+		wantErr bool
+	}{
+		{name: "valid", rate: ExchangeRate{Currency: "EUR", BaseCurrency: "RUB", Date: date, Rate: 98.5}},
+		{name: "same currencies", rate: ExchangeRate{Currency: "RUB", BaseCurrency: "RUB", Date: date, Rate: 1}, wantErr: true},
+		{name: "zero rate", rate: ExchangeRate{Currency: "EUR", BaseCurrency: "RUB", Date: date}, wantErr: true},
+		{name: "no date", rate: ExchangeRate{Currency: "EUR", BaseCurrency: "RUB", Rate: 98.5}, wantErr: true},
+		{name: "bad code", rate: ExchangeRate{Currency: "EU", BaseCurrency: "RUB", Date: date, Rate: 98.5}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.rate.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"io"
+	"strings"
+	"time"
+)
 
 type BulkTransactionRequest struct {
 	Transactions []CreateTransactionRequest `json:"transactions"`
@@ -20,43 +24,150 @@ type BulkTransactionResponse struct {
 }
 
 type CreateTransactionRequest struct {
-	Amount      float64   `json:"amount"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
+	Type           string    `json:"type"`
+	Amount         Money     `json:"amount"`
+	Currency       string    `json:"currency"`
+	Category       string    `json:"category"`
+	Description    string    `json:"description"`
+	Date           time.Time `json:"date"`
+	Tags           []string  `json:"tags"`
+	Override       bool      `json:"override"`
+	OverrideReason string    `json:"override_reason"`
 }
 
 func (dto CreateTransactionRequest) ToEntity() Transaction {
+	kind := dto.Type
+	if kind == "" {
+		kind = TransactionExpense
+	}
+
 	return Transaction{
-		Amount:      dto.Amount,
-		Category:    dto.Category,
-		Description: dto.Description,
-		Date:        dto.Date,
+		Type:           kind,
+		Amount:         SignedAmount(kind, dto.Amount),
+		Currency:       NormalizeCurrency(dto.Currency),
+		Category:       dto.Category,
+		Description:    dto.Description,
+		Date:           dto.Date,
+		Tags:           NormalizeTags(dto.Tags),
+		Override:       dto.Override,
+		OverrideReason: dto.OverrideReason,
+	}
+}
+
+// UpdateTransactionRequest меняет только заданные поля транзакции.
+type UpdateTransactionRequest struct {
+	Type           *string    `json:"type"`
+	Amount         *Money     `json:"amount"`
+	Currency       *string    `json:"currency"`
+	Category       *string    `json:"category"`
+	Description    *string    `json:"description"`
+	Date           *time.Time `json:"date"`
+	Tags           *[]string  `json:"tags"`
+	Override       bool       `json:"override"`
+	OverrideReason string     `json:"override_reason"`
+}
+
+// Apply возвращает транзакцию с изменёнными полями; сумма в запросе, как и при создании,
+// без знака, знак определяется типом.
+func (dto UpdateTransactionRequest) Apply(entity Transaction) Transaction {
+	amount := entity.Amount.Abs()
+	if dto.Amount != nil {
+		amount = *dto.Amount
+	}
+	if dto.Type != nil {
+		entity.Type = *dto.Type
+		if entity.Type == "" {
+			entity.Type = TransactionExpense
+		}
+	}
+	entity.Amount = SignedAmount(entity.Type, amount)
+
+	if dto.Currency != nil {
+		entity.Currency = NormalizeCurrency(*dto.Currency)
+	}
+
+	if dto.Category != nil {
+		entity.Category = *dto.Category
+	}
+	if dto.Description != nil {
+		entity.Description = *dto.Description
 	}
+	if dto.Date != nil {
+		entity.Date = *dto.Date
+	}
+	if dto.Tags != nil {
+		entity.Tags = NormalizeTags(*dto.Tags)
+	}
+	entity.Override = dto.Override
+	entity.OverrideReason = dto.OverrideReason
+	return entity
 }
 
 type TransactionResponse struct {
-	ID          int       `json:"id"`
-	Amount      float64   `json:"amount"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
+	ID           int             `json:"id"`
+	Type         string          `json:"type"`
+	Amount       Money           `json:"amount"`
+	Currency     string          `json:"currency"`
+	Category     string          `json:"category"`
+	Description  string          `json:"description"`
+	Date         time.Time       `json:"date"`
+	Tags         []string        `json:"tags"`
+	OverBudget   bool            `json:"over_budget"`
+	ReversesID   *int            `json:"reverses_id,omitempty"`
+	ReversedByID *int            `json:"reversed_by_id,omitempty"`
+	SplitID      *int            `json:"split_id,omitempty"`
+	Warnings     []BudgetWarning `json:"warnings,omitempty"`
+}
+
+// BudgetWarning сообщает, что транзакция пересекла порог предупреждения бюджета.
+type BudgetWarning struct {
+	Category    string  `json:"category"`
+	Threshold   float64 `json:"threshold"`
+	PercentUsed float64 `json:"percent_used"`
 }
 
 func TransactionResponseFromEntity(entity Transaction) TransactionResponse {
 	return TransactionResponse{
-		ID:          entity.ID,
-		Amount:      entity.Amount,
-		Category:    entity.Category,
-		Description: entity.Description,
-		Date:        entity.Date,
+		ID:           entity.ID,
+		Type:         entity.Type,
+		Amount:       entity.Amount,
+		Currency:     entity.Currency,
+		Category:     entity.Category,
+		Description:  entity.Description,
+		Date:         entity.Date,
+		Tags:         entity.Tags,
+		OverBudget:   entity.OverBudget,
+		ReversesID:   entity.ReversesID,
+		ReversedByID: entity.ReversedByID,
+		SplitID:      entity.SplitID,
+	}
+}
+
+type TransactionChangeResponse struct {
+	Action    string               `json:"action"`
+	Before    *TransactionSnapshot `json:"before"`
+	After     *TransactionSnapshot `json:"after"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+func TransactionChangeResponseFromEntity(entity TransactionChange) TransactionChangeResponse {
+	return TransactionChangeResponse{
+		Action:    entity.Action,
+		Before:    entity.Before,
+		After:     entity.After,
+		CreatedAt: entity.CreatedAt,
 	}
 }
 
 type CreateBudgetRequest struct {
-	Category string  `json:"category"`
-	Limit    float64 `json:"limit"`
-	Period   string  `json:"period"`
+	Category          string     `json:"category"`
+	Limit             Money      `json:"limit"`
+	Currency          string     `json:"currency"`
+	Period            string     `json:"period"`
+	Rollover          bool       `json:"rollover"`
+	WarningThresholds []float64  `json:"warning_thresholds"`
+	OverspendPolicy   string     `json:"overspend_policy"`
+	EffectiveFrom     *time.Time `json:"effective_from,omitempty"`
 }
 
 func (dto CreateBudgetRequest) ToEntity() Budget {
@@ -65,30 +176,708 @@ func (dto CreateBudgetRequest) ToEntity() Budget {
 		period = "monthly"
 	}
 
+	policy := dto.OverspendPolicy
+	if policy == "" {
+		policy = OverspendBlock
+	}
+
 	return Budget{
-		Category: dto.Category,
-		Limit:    dto.Limit,
-		Period:   period,
+		Category:          dto.Category,
+		Limit:             dto.Limit,
+		Currency:          NormalizeCurrency(dto.Currency),
+		Period:            period,
+		Rollover:          dto.Rollover,
+		WarningThresholds: dto.WarningThresholds,
+		OverspendPolicy:   policy,
+		EffectiveFrom:     dto.EffectiveFrom,
 	}
 }
 
 type BudgetResponse struct {
-	Category string  `json:"category"`
-	Limit    float64 `json:"limit"`
-	Period   string  `json:"period"`
+	Category          string                `json:"category"`
+	Limit             Money                 `json:"limit"`
+	EffectiveLimit    Money                 `json:"effective_limit"`
+	Currency          string                `json:"currency"`
+	Period            string                `json:"period"`
+	Rollover          bool                  `json:"rollover"`
+	WarningThresholds []float64             `json:"warning_thresholds"`
+	OverspendPolicy   string                `json:"overspend_policy"`
+	Status            *BudgetStatusResponse `json:"status,omitempty"`
+}
+
+type ListBudgetsRequest struct {
+	WithStatus bool `json:"with_status"`
 }
 
 func BudgetResponseFromEntity(entity Budget) BudgetResponse {
 	return BudgetResponse{
-		Category: entity.Category,
-		Limit:    entity.Limit,
-		Period:   entity.Period,
+		Category:          entity.Category,
+		Limit:             entity.Limit,
+		EffectiveLimit:    entity.Limit,
+		Currency:          entity.Currency,
+		Period:            entity.Period,
+		Rollover:          entity.Rollover,
+		WarningThresholds: entity.WarningThresholds,
+		OverspendPolicy:   entity.OverspendPolicy,
+	}
+}
+
+type BudgetVersionResponse struct {
+	Period        string     `json:"period"`
+	Limit         Money      `json:"limit"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func BudgetVersionResponseFromEntity(entity BudgetVersion) BudgetVersionResponse {
+	return BudgetVersionResponse{
+		Period:        entity.Period,
+		Limit:         entity.Limit,
+		EffectiveFrom: entity.EffectiveFrom,
+		CreatedAt:     entity.CreatedAt,
+	}
+}
+
+type CreateLimitOverrideRequest struct {
+	Category string    `json:"category"`
+	Kind     string    `json:"kind"`
+	Amount   Money     `json:"amount"`
+	StartsOn time.Time `json:"starts_on"`
+	EndsOn   time.Time `json:"ends_on"`
+	Reason   string    `json:"reason"`
+}
+
+func (dto CreateLimitOverrideRequest) ToEntity() LimitOverride {
+	return LimitOverride{
+		Category: dto.Category,
+		Kind:     dto.Kind,
+		Amount:   dto.Amount,
+		StartsOn: dto.StartsOn,
+		EndsOn:   dto.EndsOn,
+		Reason:   dto.Reason,
+	}
+}
+
+type LimitOverrideResponse struct {
+	ID        int       `json:"id"`
+	Period    string    `json:"period"`
+	Kind      string    `json:"kind"`
+	Amount    Money     `json:"amount"`
+	StartsOn  time.Time `json:"starts_on"`
+	EndsOn    time.Time `json:"ends_on"`
+	Reason    string    `json:"reason"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func LimitOverrideResponseFromEntity(entity LimitOverride, at time.Time) LimitOverrideResponse {
+	return LimitOverrideResponse{
+		ID:        entity.ID,
+		Period:    entity.Period,
+		Kind:      entity.Kind,
+		Amount:    entity.Amount,
+		StartsOn:  entity.StartsOn,
+		EndsOn:    entity.EndsOn,
+		Reason:    entity.Reason,
+		Active:    entity.ActiveOn(at),
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+// BudgetHistoryResponse — версии лимитов и временные изменения, включая истёкшие.
+type BudgetHistoryResponse struct {
+	Versions  []BudgetVersionResponse `json:"versions"`
+	Overrides []LimitOverrideResponse `json:"overrides"`
+}
+
+type CreateBudgetPoolRequest struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+	Limit      Money    `json:"limit"`
+	Period     string   `json:"period"`
+}
+
+func (dto CreateBudgetPoolRequest) ToEntity() BudgetPool {
+	period := dto.Period
+	if period == "" {
+		period = PeriodMonthly
+	}
+
+	return BudgetPool{
+		Name:       dto.Name,
+		Categories: dto.Categories,
+		Limit:      dto.Limit,
+		Period:     period,
+	}
+}
+
+type BudgetPoolResponse struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+	Limit      Money    `json:"limit"`
+	Period     string   `json:"period"`
+}
+
+func BudgetPoolResponseFromEntity(entity BudgetPool) BudgetPoolResponse {
+	return BudgetPoolResponse{
+		Name:       entity.Name,
+		Categories: entity.Categories,
+		Limit:      entity.Limit,
+		Period:     entity.Period,
+	}
+}
+
+type BudgetStatusResponse struct {
+	Category    string    `json:"category"`
+	Period      string    `json:"period"`
+	Limit       Money     `json:"limit"`
+	Spent       Money     `json:"spent"`
+	Remaining   Money     `json:"remaining"`
+	Currency    string    `json:"currency"`
+	PercentUsed float64   `json:"percent_used"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	DaysLeft    int       `json:"days_left"`
+}
+
+func BudgetStatusResponseFromEntity(entity BudgetStatus) BudgetStatusResponse {
+	return BudgetStatusResponse{
+		Category:    entity.Category,
+		Period:      entity.Period,
+		Limit:       entity.Limit,
+		Spent:       entity.Spent,
+		Remaining:   entity.Remaining,
+		Currency:    entity.Currency,
+		PercentUsed: entity.PercentUsed,
+		PeriodStart: entity.PeriodStart,
+		PeriodEnd:   entity.PeriodEnd,
+		DaysLeft:    entity.DaysLeft,
 	}
 }
 
-type SpendingSummary map[string]float64
+type SpendingSummary map[string]Money
+
+const (
+	SpendingViewTree = "tree"
+	SpendingViewFlat = "flat"
+)
 
 type GetSpendingSummaryRequest struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	View     string    `json:"view"`
+	Currency string    `json:"currency"` // валюта отчёта, по умолчанию BaseCurrency
+	Tags     []string  `json:"tags"`     // только транзакции хотя бы с одной из меток
+}
+
+type CategoryResponse struct {
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+}
+
+func CategoryResponseFromEntity(entity Category) CategoryResponse {
+	return CategoryResponse{
+		Name:   entity.Name,
+		Parent: CategoryParent(entity.Name),
+	}
+}
+
+type GetBudgetForecastRequest struct {
+	Date time.Time `json:"date"`
+}
+
+type BudgetForecastResponse struct {
+	Category            string     `json:"category"`
+	Period              string     `json:"period"`
+	Limit               Money      `json:"limit"`
+	Spent               Money      `json:"spent"`
+	PeriodStart         time.Time  `json:"period_start"`
+	PeriodEnd           time.Time  `json:"period_end"`
+	ElapsedDays         int        `json:"elapsed_days"`
+	TotalDays           int        `json:"total_days"`
+	DailyVelocity       Money      `json:"daily_velocity"`
+	IdealSpent          Money      `json:"ideal_spent"`
+	ProjectedTotal      Money      `json:"projected_total"`
+	ProjectedExhaustion *time.Time `json:"projected_exhaustion"`
+	Status              string     `json:"status"`
+}
+
+func BudgetForecastResponseFromEntity(entity BudgetForecast) BudgetForecastResponse {
+	return BudgetForecastResponse{
+		Category:            entity.Category,
+		Period:              entity.Period,
+		Limit:               entity.Limit,
+		Spent:               entity.Spent,
+		PeriodStart:         entity.PeriodStart,
+		PeriodEnd:           entity.PeriodEnd,
+		ElapsedDays:         entity.ElapsedDays,
+		TotalDays:           entity.TotalDays,
+		DailyVelocity:       entity.DailyVelocity,
+		IdealSpent:          entity.IdealSpent,
+		ProjectedTotal:      entity.ProjectedTotal,
+		ProjectedExhaustion: entity.ProjectedExhaustion,
+		Status:              entity.Status,
+	}
+}
+
+type GetOverspendReportRequest struct {
 	From time.Time `json:"from"`
 	To   time.Time `json:"to"`
 }
+
+type OverspendResponse struct {
+	Transaction    TransactionResponse `json:"transaction"`
+	BudgetCategory string              `json:"budget_category"`
+	Policy         string              `json:"policy"`
+	Reason         string              `json:"reason"`
+	Limit          Money               `json:"limit"`
+	Spent          Money               `json:"spent"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+func OverspendResponseFromEntity(entity OverspendRecord) OverspendResponse {
+	return OverspendResponse{
+		Transaction:    TransactionResponseFromEntity(entity.Transaction),
+		BudgetCategory: entity.BudgetCategory,
+		Policy:         entity.Policy,
+		Reason:         entity.Reason,
+		Limit:          entity.Limit,
+		Spent:          entity.Spent,
+		CreatedAt:      entity.CreatedAt,
+	}
+}
+
+type GetBudgetSuggestionsRequest struct {
+	Period     string    `json:"period"`
+	Periods    int       `json:"periods"`
+	Percentile float64   `json:"percentile"`
+	Categories []string  `json:"categories"`
+	Date       time.Time `json:"date"`
+}
+
+type PeriodAmountResponse struct {
+	PeriodStart time.Time `json:"period_start"`
+	Amount      Money     `json:"amount"`
+}
+
+type BudgetSuggestionResponse struct {
+	Category   string                 `json:"category"`
+	Period     string                 `json:"period"`
+	Limit      Money                  `json:"limit"`
+	Percentile float64                `json:"percentile"`
+	Basis      []PeriodAmountResponse `json:"basis"`
+	Outliers   []PeriodAmountResponse `json:"outliers"`
+}
+
+func BudgetSuggestionResponseFromEntity(entity BudgetSuggestion) BudgetSuggestionResponse {
+	return BudgetSuggestionResponse{
+		Category:   entity.Category,
+		Period:     entity.Period,
+		Limit:      entity.Limit,
+		Percentile: entity.Percentile,
+		Basis:      periodAmountResponses(entity.Basis),
+		Outliers:   periodAmountResponses(entity.Outliers),
+	}
+}
+
+func periodAmountResponses(amounts []PeriodAmount) []PeriodAmountResponse {
+	responses := make([]PeriodAmountResponse, len(amounts))
+	for i, amount := range amounts {
+		responses[i] = PeriodAmountResponse{
+			PeriodStart: amount.PeriodStart,
+			Amount:      amount.Amount,
+		}
+	}
+	return responses
+}
+
+type GetCashFlowRequest struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Currency string    `json:"currency"` // валюта отчёта, по умолчанию BaseCurrency
+}
+
+type CashFlowResponse struct {
+	Income      Money  `json:"income"`
+	Expenses    Money  `json:"expenses"`
+	Refunds     Money  `json:"refunds"`
+	NetSpending Money  `json:"net_spending"`
+	Net         Money  `json:"net"`
+	Currency    string `json:"currency"`
+}
+
+func CashFlowResponseFromEntity(entity CashFlow) CashFlowResponse {
+	return CashFlowResponse{
+		Income:      entity.Income,
+		Expenses:    entity.Expenses,
+		Refunds:     entity.Refunds,
+		NetSpending: entity.NetSpending(),
+		Net:         entity.Net(),
+		Currency:    entity.Currency,
+	}
+}
+
+type CreateExchangeRateRequest struct {
+	Currency     string    `json:"currency"`
+	BaseCurrency string    `json:"base_currency"`
+	Date         time.Time `json:"date"`
+	Rate         float64   `json:"rate"`
+}
+
+func (dto CreateExchangeRateRequest) ToEntity() ExchangeRate {
+	return ExchangeRate{
+		Currency:     NormalizeCurrency(dto.Currency),
+		BaseCurrency: NormalizeCurrency(dto.BaseCurrency),
+		Date:         dto.Date,
+		Rate:         dto.Rate,
+	}
+}
+
+// ListExchangeRatesRequest отбирает курсы по валютам; пустое поле не ограничивает выборку.
+type ListExchangeRatesRequest struct {
+	Currency     string `json:"currency"`
+	BaseCurrency string `json:"base_currency"`
+}
+
+type ExchangeRateResponse struct {
+	Currency     string    `json:"currency"`
+	BaseCurrency string    `json:"base_currency"`
+	Date         time.Time `json:"date"`
+	Rate         float64   `json:"rate"`
+}
+
+func ExchangeRateResponseFromEntity(entity ExchangeRate) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		Currency:     entity.Currency,
+		BaseCurrency: entity.BaseCurrency,
+		Date:         entity.Date,
+		Rate:         entity.Rate,
+	}
+}
+
+// ListTransactionsRequest отбирает транзакции; пустые поля не ограничивают выборку.
+// Cursor — next_cursor предыдущей страницы.
+type ListTransactionsRequest struct {
+	Category    string     `json:"category"`
+	From        *time.Time `json:"from,omitempty"`
+	To          *time.Time `json:"to,omitempty"`
+	MinAmount   *Money     `json:"min_amount,omitempty"`
+	MaxAmount   *Money     `json:"max_amount,omitempty"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags"`
+	Sort        string     `json:"sort"`
+	Limit       int        `json:"limit"`
+	Cursor      string     `json:"cursor"`
+}
+
+// ToFilter собирает фильтр; по умолчанию новые транзакции идут первыми по DefaultPageSize на страницу.
+func (dto ListTransactionsRequest) ToFilter() (TransactionFilter, error) {
+	filter := TransactionFilter{
+		Category:    strings.TrimSpace(dto.Category),
+		From:        dto.From,
+		To:          dto.To,
+		MinAmount:   dto.MinAmount,
+		MaxAmount:   dto.MaxAmount,
+		Description: strings.TrimSpace(dto.Description),
+		Tags:        NormalizeTags(dto.Tags),
+		Sort:        dto.Sort,
+		Limit:       dto.Limit,
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = SortDateDesc
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if dto.Cursor != "" {
+		cursor, err := DecodeCursor(dto.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
+
+// TransactionPageResponse — страница списка транзакций. NextCursor пуст на последней странице.
+type TransactionPageResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+type SearchTransactionsRequest struct {
+	Query    string     `json:"q"`
+	Category string     `json:"category"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Limit    int        `json:"limit"`
+}
+
+func (dto SearchTransactionsRequest) ToSearch() TransactionSearch {
+	limit := dto.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+
+	return TransactionSearch{
+		Query:    strings.TrimSpace(dto.Query),
+		Category: strings.TrimSpace(dto.Category),
+		From:     dto.From,
+		To:       dto.To,
+		Limit:    limit,
+	}
+}
+
+type SearchResultResponse struct {
+	Transaction TransactionResponse `json:"transaction"`
+	Rank        float64             `json:"rank"`
+	Headline    string              `json:"headline"`
+}
+
+func SearchResultResponseFromEntity(entity SearchResult) SearchResultResponse {
+	return SearchResultResponse{
+		Transaction: TransactionResponseFromEntity(entity.Transaction),
+		Rank:        entity.Rank,
+		Headline:    entity.Headline,
+	}
+}
+
+type GetTagSummaryRequest struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Currency string    `json:"currency"`
+	Tags     []string  `json:"tags"` // только эти метки; пусто — все
+}
+
+type TagSpendingResponse struct {
+	Tag          string `json:"tag"`
+	Amount       Money  `json:"amount"`
+	Transactions int    `json:"transactions"`
+}
+
+func TagSpendingResponseFromEntity(entity TagSpending) TagSpendingResponse {
+	return TagSpendingResponse{
+		Tag:          entity.Tag,
+		Amount:       entity.Amount,
+		Transactions: entity.Transactions,
+	}
+}
+
+// CreateSplitRequest разносит платёж Amount по строкам Lines. Описание, дата, метки
+// и подтверждение превышения общие для всех строк, если у строки нет своего описания.
+type CreateSplitRequest struct {
+	Amount         Money              `json:"amount"`
+	Currency       string             `json:"currency"`
+	Description    string             `json:"description"`
+	Date           time.Time          `json:"date"`
+	Tags           []string           `json:"tags"`
+	Override       bool               `json:"override"`
+	OverrideReason string             `json:"override_reason"`
+	Lines          []SplitLineRequest `json:"lines"`
+}
+
+type SplitLineRequest struct {
+	Category    string `json:"category"`
+	Amount      Money  `json:"amount"`
+	Description string `json:"description"`
+}
+
+func (dto CreateSplitRequest) ToEntity() Split {
+	split := Split{
+		Amount:      dto.Amount,
+		Currency:    NormalizeCurrency(dto.Currency),
+		Description: dto.Description,
+		Date:        dto.Date,
+		Lines:       make([]Transaction, len(dto.Lines)),
+	}
+
+	tags := NormalizeTags(dto.Tags)
+	for i, line := range dto.Lines {
+		description := line.Description
+		if description == "" {
+			description = dto.Description
+		}
+		split.Lines[i] = Transaction{
+			Type:           TransactionExpense,
+			Amount:         line.Amount,
+			Currency:       split.Currency,
+			Category:       line.Category,
+			Description:    description,
+			Date:           dto.Date,
+			Tags:           tags,
+			Override:       dto.Override,
+			OverrideReason: dto.OverrideReason,
+		}
+	}
+
+	return split
+}
+
+type SplitResponse struct {
+	ID          int                   `json:"id"`
+	Amount      Money                 `json:"amount"`
+	Currency    string                `json:"currency"`
+	Description string                `json:"description"`
+	Date        time.Time             `json:"date"`
+	Lines       []TransactionResponse `json:"lines"`
+	Warnings    []BudgetWarning       `json:"warnings,omitempty"`
+}
+
+func SplitResponseFromEntity(entity Split) SplitResponse {
+	lines := make([]TransactionResponse, len(entity.Lines))
+	for i, line := range entity.Lines {
+		lines[i] = TransactionResponseFromEntity(line)
+	}
+
+	return SplitResponse{
+		ID:          entity.ID,
+		Amount:      entity.Amount,
+		Currency:    entity.Currency,
+		Description: entity.Description,
+		Date:        entity.Date,
+		Lines:       lines,
+	}
+}
+
+type CreateRecurringRequest struct {
+	Type        string     `json:"type"`
+	Amount      Money      `json:"amount"`
+	Currency    string     `json:"currency"`
+	Category    string     `json:"category"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags"`
+	Frequency   string     `json:"frequency"`
+	Interval    int        `json:"interval"`
+	DayOfMonth  int        `json:"day_of_month"`
+	StartsOn    time.Time  `json:"starts_on"`
+	EndsOn      *time.Time `json:"ends_on,omitempty"`
+}
+
+func (dto CreateRecurringRequest) ToEntity() RecurringTransaction {
+	kind := dto.Type
+	if kind == "" {
+		kind = TransactionExpense
+	}
+
+	interval := dto.Interval
+	if interval == 0 {
+		interval = 1
+	}
+
+	return RecurringTransaction{
+		Type:        kind,
+		Amount:      dto.Amount,
+		Currency:    NormalizeCurrency(dto.Currency),
+		Category:    dto.Category,
+		Description: dto.Description,
+		Tags:        NormalizeTags(dto.Tags),
+		Frequency:   dto.Frequency,
+		Interval:    interval,
+		DayOfMonth:  dto.DayOfMonth,
+		StartsOn:    dto.StartsOn,
+		EndsOn:      dto.EndsOn,
+		Active:      true,
+	}
+}
+
+type RecurringResponse struct {
+	ID          int        `json:"id"`
+	Type        string     `json:"type"`
+	Amount      Money      `json:"amount"`
+	Currency    string     `json:"currency"`
+	Category    string     `json:"category"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags"`
+	Frequency   string     `json:"frequency"`
+	Interval    int        `json:"interval"`
+	DayOfMonth  int        `json:"day_of_month"`
+	StartsOn    time.Time  `json:"starts_on"`
+	EndsOn      *time.Time `json:"ends_on,omitempty"`
+	Active      bool       `json:"active"`
+}
+
+func RecurringResponseFromEntity(entity RecurringTransaction) RecurringResponse {
+	return RecurringResponse{
+		ID:          entity.ID,
+		Type:        entity.Type,
+		Amount:      entity.Amount,
+		Currency:    entity.Currency,
+		Category:    entity.Category,
+		Description: entity.Description,
+		Tags:        entity.Tags,
+		Frequency:   entity.Frequency,
+		Interval:    entity.Interval,
+		DayOfMonth:  entity.DayOfMonth,
+		StartsOn:    entity.StartsOn,
+		EndsOn:      entity.EndsOn,
+		Active:      entity.Active,
+	}
+}
+
+// ListOccurrencesRequest отбирает проведения; RecurringID 0 и пустой Status не ограничивают выборку.
+type ListOccurrencesRequest struct {
+	RecurringID int    `json:"recurring_id"`
+	Status      string `json:"status"`
+}
+
+type OccurrenceResponse struct {
+	ID            int       `json:"id"`
+	RecurringID   int       `json:"recurring_id"`
+	DueOn         time.Time `json:"due_on"`
+	Status        string    `json:"status"`
+	TransactionID *int      `json:"transaction_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func OccurrenceResponseFromEntity(entity Occurrence) OccurrenceResponse {
+	return OccurrenceResponse{
+		ID:            entity.ID,
+		RecurringID:   entity.RecurringID,
+		DueOn:         entity.DueOn,
+		Status:        entity.Status,
+		TransactionID: entity.TransactionID,
+		Error:         entity.Error,
+		CreatedAt:     entity.CreatedAt,
+	}
+}
+
+// UploadAttachmentRequest — загружаемый файл; Content читается один раз при сохранении.
+type UploadAttachmentRequest struct {
+	TransactionID int       `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	Content       io.Reader `json:"-"`
+}
+
+type AttachmentResponse struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	SHA256        string    `json:"sha256"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func AttachmentResponseFromEntity(entity Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:            entity.ID,
+		TransactionID: entity.TransactionID,
+		Filename:      entity.Filename,
+		ContentType:   entity.ContentType,
+		Size:          entity.Size,
+		SHA256:        entity.SHA256,
+		CreatedAt:     entity.CreatedAt,
+	}
+}
+
+// IdempotentRequest — запрос с заголовком Idempotency-Key.
+type IdempotentRequest struct {
+	Key    string `json:"key"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   []byte `json:"-"`
+}
@@ -2,35 +2,78 @@ package domain
 
 import (
 	"errors"
+	"math"
 	"strings"
 	"time"
 )
 
 type Transaction struct {
 	ID          int
-	Amount      float64
+	Type        string // "expense" (по умолчанию), "refund" или "income"
+	Amount      Money  // со знаком: возвраты отрицательные, в валюте Currency
+	Currency    string // исходная валюта транзакции, по умолчанию BaseCurrency
 	Category    string
 	Description string
 	Date        time.Time
+	Tags        []string // метки в нижнем регистре, без повторов
+	OverBudget  bool
+
+	ReversesID   *int // для сторнирующей записи — исходная транзакция
+	ReversedByID *int // для сторнированной транзакции — сторнирующая запись
+	SplitID      *int // для строки разнесённого платежа — сам платёж
+
+	// Явное подтверждение превышения для бюджетов с политикой override.
+	Override       bool
+	OverrideReason string
 }
 
 func (t Transaction) Validate() error {
-	if t.Amount <= 0 {
+	if ValidateTransactionType(t.Type) != nil {
+		return errors.New("тип транзакции должен быть 'expense', 'refund' или 'income'")
+	}
+	if t.Type == TransactionRefund && t.Amount >= 0 {
+		return errors.New("сумма возврата должна быть отрицательной")
+	}
+	if t.Type != TransactionRefund && t.Amount <= 0 {
 		return errors.New("сумма транзакции должна быть положительным числом")
 	}
+	if ValidateCurrency(t.Currency) != nil {
+		return errors.New("код валюты должен состоять из трёх латинских букв")
+	}
 	if strings.TrimSpace(t.Category) == "" {
 		return errors.New("категория транзакции не может быть пустой")
 	}
 	if t.Date.IsZero() {
 		return errors.New("дата транзакции должна быть указана")
 	}
+	if ValidateTags(t.Tags) != nil {
+		return errors.New("метка не может быть длиннее 50 символов или содержать запятую")
+	}
 	return nil
 }
 
 type Budget struct {
-	Category string
-	Limit    float64
-	Period   string // "monthly", "weekly", "daily"
+	ID                int
+	Category          string
+	Limit             Money
+	Currency          string     // валюта лимита, в неё пересчитываются расходы
+	Period            string     // "monthly", "weekly", "daily" или календарный: "2024-01", "2024-W05", "2024-01-01..2024-01-15"
+	Rollover          bool       // перенос остатка (или перерасхода) прошлого периода, только для повторяющихся периодов
+	WarningThresholds []float64  // пороги предупреждений в процентах от лимита, например 80 и 95
+	OverspendPolicy   string     // "block" (по умолчанию), "allow" или "override"
+	EffectiveFrom     *time.Time // с какой даты действует новый лимит при изменении бюджета
+	CreatedAt         time.Time
+}
+
+// BudgetVersion — версия лимита бюджета. EffectiveFrom == nil у исходного лимита,
+// действующего с самого начала.
+type BudgetVersion struct {
+	BudgetID      int
+	Category      string
+	Period        string
+	Limit         Money
+	EffectiveFrom *time.Time
+	CreatedAt     time.Time
 }
 
 func (b Budget) Validate() error {
@@ -40,8 +83,103 @@ func (b Budget) Validate() error {
 	if b.Limit <= 0 {
 		return errors.New("лимит бюджета должен быть положительным числом")
 	}
-	if b.Period != "" && b.Period != "monthly" && b.Period != "weekly" && b.Period != "daily" {
-		return errors.New("период должен быть 'monthly', 'weekly', 'daily' или пустым")
+	if err := ValidatePeriod(b.Period); err != nil {
+		return errors.New("период должен быть 'monthly', 'weekly', 'daily', календарным ('2024-01', '2024-W05', '2024-01-01..2024-01-15') или пустым")
+	}
+	if b.Rollover && !IsRecurringPeriod(b.Period) {
+		return errors.New("перенос остатка доступен только для повторяющихся периодов")
+	}
+	for _, threshold := range b.WarningThresholds {
+		if threshold <= 0 || threshold >= 100 {
+			return errors.New("порог предупреждения должен быть в диапазоне (0, 100)")
+		}
+	}
+	if ValidateCurrency(b.Currency) != nil {
+		return errors.New("код валюты должен состоять из трёх латинских букв")
+	}
+	if ValidateOverspendPolicy(b.OverspendPolicy) != nil {
+		return errors.New("политика превышения должна быть 'block', 'allow' или 'override'")
 	}
 	return nil
 }
+
+// PeriodRange возвращает границы периода бюджета, в который попадает момент at.
+func (b Budget) PeriodRange(at time.Time) (time.Time, time.Time) {
+	return PeriodRange(b.Period, at)
+}
+
+// PreviousPeriodRange возвращает границы периода, предшествующего периоду с моментом at.
+func (b Budget) PreviousPeriodRange(at time.Time) (time.Time, time.Time) {
+	from, _ := b.PeriodRange(at)
+	return b.PeriodRange(from.Add(-time.Second))
+}
+
+// EffectiveLimit возвращает лимит с учётом переноса: к базовому лимиту добавляется
+// неизрасходованный остаток лимита прошлого периода или вычитается его перерасход.
+func (b Budget) EffectiveLimit(previousLimit, previousSpent Money) Money {
+	if !b.Rollover {
+		return b.Limit
+	}
+	return b.Limit + (previousLimit - previousSpent)
+}
+
+// CrossedThresholds возвращает пороги предупреждений, которые пересекает расход,
+// выросший с before до after при лимите limit.
+func (b Budget) CrossedThresholds(limit, before, after Money) []float64 {
+	var crossed []float64
+	for _, threshold := range b.WarningThresholds {
+		boundary := limit.Percent(threshold)
+		if before < boundary && after >= boundary {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// BudgetStatus — состояние бюджета в конкретном периоде.
+type BudgetStatus struct {
+	Category    string
+	Period      string
+	Limit       Money
+	Spent       Money
+	Remaining   Money
+	Currency    string
+	PercentUsed float64
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	DaysLeft    int
+}
+
+// NewBudgetStatus собирает состояние бюджета. Остаток не бывает отрицательным,
+// DaysLeft считает оставшиеся дни периода включая день at.
+func NewBudgetStatus(budget Budget, limit, spent Money, from, to, at time.Time) *BudgetStatus {
+	status := &BudgetStatus{
+		Category:    budget.Category,
+		Period:      budget.Period,
+		Limit:       limit,
+		Spent:       spent,
+		Currency:    budget.Currency,
+		PeriodStart: from,
+		PeriodEnd:   to,
+	}
+
+	if spent < limit {
+		status.Remaining = limit - spent
+	}
+
+	if limit > 0 {
+		status.PercentUsed = math.Round(spent.Ratio(limit)*10000) / 100
+	}
+
+	// Считаем в UTC по календарным датам, чтобы переход на летнее время не съедал день
+	first := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	if start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC); first.Before(start) {
+		first = start
+	}
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if !first.After(last) {
+		status.DaysLeft = int(last.Sub(first).Hours()/24) + 1
+	}
+
+	return status
+}
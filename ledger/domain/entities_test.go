@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBudgetCrossedThresholds(t *testing.T) {
+	t.Parallel()
+
+	budget := Budget{Category: "food", Limit: 1000, WarningThresholds: []float64{80, 95}}
+
+	testCases := []struct {
+		name          string
+		before, after Money
+		want          []float64
+	}{
+		{name: "below all thresholds", before: 100, after: 700, want: nil},
+		{name: "crosses first threshold", before: 700, after: 850, want: []float64{80}},
+		{name: "lands exactly on threshold", before: 700, after: 800, want: []float64{80}},
+		{name: "crosses both thresholds", before: 700, after: 990, want: []float64{80, 95}},
+		{name: "already past threshold", before: 850, after: 900, want: nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := budget.CrossedThresholds(budget.Limit, tc.before, tc.after)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewBudgetStatus(t *testing.T) {
+	t.Parallel()
+
+	budget := Budget{Category: "food", Limit: 1000, Period: PeriodMonthly}
+	at := time.Date(2024, 1, 29, 12, 0, 0, 0, time.UTC)
+	from, to := budget.PeriodRange(at)
+
+	status := NewBudgetStatus(budget, 1000, 1250, from, to, at)
+
+	if status.Remaining != 0 {
+		t.Errorf("Expected remaining 0 for overspent budget, got %v", status.Remaining)
+	}
+	if status.PercentUsed != 125 {
+		t.Errorf("Expected 125%% used, got %v", status.PercentUsed)
+	}
+	if status.DaysLeft != 3 {
+		t.Errorf("Expected 3 days left, got %d", status.DaysLeft)
+	}
+
+	past := NewBudgetStatus(budget, 1000, 0, from, to, at.AddDate(0, 1, 0))
+	if past.DaysLeft != 0 {
+		t.Errorf("Expected 0 days left after period end, got %d", past.DaysLeft)
+	}
+}
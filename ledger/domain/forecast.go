@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	ForecastOnTrack   = "on_track"
+	ForecastOverPace  = "over_pace"
+	ForecastExhausted = "exhausted"
+)
+
+// BudgetForecast — прогноз расходов бюджета до конца периода при текущей скорости трат.
+type BudgetForecast struct {
+	Category    string
+	Period      string
+	Limit       Money
+	Spent       Money
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	ElapsedDays int
+	TotalDays   int
+	// DailyVelocity — средний расход в день с начала периода.
+	DailyVelocity Money
+	// IdealSpent — сколько было бы потрачено при равномерном расходе лимита.
+	IdealSpent     Money
+	ProjectedTotal Money
+	// ProjectedExhaustion — день, когда при текущей скорости будет исчерпан лимит;
+	// nil, если лимит уже исчерпан или до конца периода его хватит.
+	ProjectedExhaustion *time.Time
+	Status              string
+}
+
+// NewBudgetForecast строит прогноз по состоянию бюджета на момент at.
+func NewBudgetForecast(status BudgetStatus, at time.Time) BudgetForecast {
+	start := calendarDay(status.PeriodStart)
+	end := calendarDay(status.PeriodEnd)
+	today := calendarDay(at)
+
+	totalDays := daysBetween(start, end) + 1
+	elapsedDays := daysBetween(start, today) + 1
+	if elapsedDays < 1 {
+		elapsedDays = 1
+	}
+	if elapsedDays > totalDays {
+		elapsedDays = totalDays
+	}
+
+	forecast := BudgetForecast{
+		Category:    status.Category,
+		Period:      status.Period,
+		Limit:       status.Limit,
+		Spent:       status.Spent,
+		PeriodStart: status.PeriodStart,
+		PeriodEnd:   status.PeriodEnd,
+		ElapsedDays: elapsedDays,
+		TotalDays:   totalDays,
+	}
+
+	spent, limit := float64(status.Spent), float64(status.Limit)
+
+	forecast.DailyVelocity = Money(math.Round(spent / float64(elapsedDays)))
+	forecast.IdealSpent = Money(math.Round(limit * float64(elapsedDays) / float64(totalDays)))
+	forecast.ProjectedTotal = Money(math.Round(spent / float64(elapsedDays) * float64(totalDays)))
+
+	switch {
+	case status.Spent >= status.Limit:
+		forecast.Status = ForecastExhausted
+	case forecast.ProjectedTotal > status.Limit:
+		forecast.Status = ForecastOverPace
+		day := int(math.Ceil(limit*float64(elapsedDays)/spent)) - 1
+		exhaustion := status.PeriodStart.AddDate(0, 0, day)
+		forecast.ProjectedExhaustion = &exhaustion
+	default:
+		forecast.Status = ForecastOnTrack
+	}
+
+	return forecast
+}
+
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBudgetForecast(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 30, 23, 59, 59, 0, time.UTC)
+	at := time.Date(2024, 4, 10, 18, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		spent          Money
+		wantStatus     string
+		wantProjected  Money
+		wantExhaustion *time.Time
+	}{
+		{
+			name:          "on track",
+			spent:         200000,
+			wantStatus:    ForecastOnTrack,
+			wantProjected: 600000,
+		},
+		{
+			name:           "over pace",
+			spent:          500000,
+			wantStatus:     ForecastOverPace,
+			wantProjected:  1500000,
+			wantExhaustion: ptrTime(time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:          "already exhausted",
+			spent:         1100000,
+			wantStatus:    ForecastExhausted,
+			wantProjected: 3300000,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			status := BudgetStatus{Category: "food", Limit: 1000000, Spent: tc.spent, PeriodStart: from, PeriodEnd: to}
+			forecast := NewBudgetForecast(status, at)
+
+			if forecast.TotalDays != 30 || forecast.ElapsedDays != 10 {
+				t.Errorf("Expected 10 of 30 days elapsed, got %d of %d", forecast.ElapsedDays, forecast.TotalDays)
+			}
+			if forecast.IdealSpent != 333333 {
+				t.Errorf("Expected ideal spent 3333.33, got %v", forecast.IdealSpent)
+			}
+			if forecast.Status != tc.wantStatus {
+				t.Errorf("Expected status %s, got %s", tc.wantStatus, forecast.Status)
+			}
+			if forecast.ProjectedTotal != tc.wantProjected {
+				t.Errorf("Expected projected total %v, got %v", tc.wantProjected, forecast.ProjectedTotal)
+			}
+
+			switch {
+			case tc.wantExhaustion == nil && forecast.ProjectedExhaustion != nil:
+				t.Errorf("Expected no exhaustion date, got %v", *forecast.ProjectedExhaustion)
+			case tc.wantExhaustion != nil && forecast.ProjectedExhaustion == nil:
+				t.Errorf("Expected exhaustion on %v, got nil", *tc.wantExhaustion)
+			case tc.wantExhaustion != nil && !forecast.ProjectedExhaustion.Equal(*tc.wantExhaustion):
+				t.Errorf("Expected exhaustion on %v, got %v", *tc.wantExhaustion, *forecast.ProjectedExhaustion)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
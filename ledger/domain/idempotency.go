@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// MaxIdempotencyKeyLength — наибольшая длина ключа идемпотентности.
+const MaxIdempotencyKeyLength = 255
+
+var (
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// IdempotencyRecord — запрос, выполненный с ключом идемпотентности. Пока запрос
+// выполняется, Response равен nil.
+type IdempotencyRecord struct {
+	Key         string
+	Method      string
+	Path        string
+	Fingerprint string
+	Response    *StoredResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// StoredResponse — сохранённый ответ, который отдаётся на повтор запроса.
+type StoredResponse struct {
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+}
+
+// IdempotencyFingerprint отпечаток запроса: метод, путь и тело. Повтор с тем же ключом
+// принимается только с тем же отпечатком.
+func IdempotencyFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func ValidateIdempotencyKey(key string) error {
+	if key == "" {
+		return errors.New("idempotency key cannot be empty")
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		return errors.New("idempotency key cannot be longer than 255 characters")
+	}
+	for _, r := range key {
+		if r < 0x21 || r > 0x7e {
+			return errors.New("idempotency key must contain only visible ASCII characters")
+		}
+	}
+	return nil
+}
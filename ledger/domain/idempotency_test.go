@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	t.Parallel()
+
+	base := IdempotencyFingerprint("POST", "/api/transactions", []byte(`{"amount":"500"}`))
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		same   bool
+	}{
+		{name: "same request", method: "POST", path: "/api/transactions", body: `{"amount":"500"}`, same: true},
+		{name: "other body", method: "POST", path: "/api/transactions", body: `{"amount":"501"}`},
+		{name: "other path", method: "POST", path: "/api/transactions/bulk", body: `{"amount":"500"}`},
+		{name: "shifted boundary", method: "POST", path: "/api/transactions{", body: `"amount":"500"}`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := IdempotencyFingerprint(tc.method, tc.path, []byte(tc.body))
+			if (got == base) != tc.same {
+				t.Errorf("Expected same fingerprint %v, got %v", tc.same, got == base)
+			}
+		})
+	}
+}
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "uuid", key: "6f1c2a9e-3b7d-4c55-9a0e-1d2f3a4b5c6d"},
+		{name: "empty", key: "", wantErr: true},
+		{name: "too long", key: strings.Repeat("k", MaxIdempotencyKeyLength+1), wantErr: true},
+		{name: "space", key: "my key", wantErr: true},
+		{name: "non ascii", key: "ключ", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateIdempotencyKey(tc.key)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money — денежная сумма в копейках (сотых долях). Совпадает с точностью NUMERIC(14,2)
+// в базе и складывается точно, в отличие от float64. В JSON передаётся строкой "1234.56";
+// на входе принимаются и строка, и число.
+type Money int64
+
+const moneyScale = 100
+
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// ParseMoney разбирает десятичную запись вида "1234.56", "-0.5" или "100".
+// Значащих знаков после точки может быть не больше двух.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" || len(whole) > 16 || len(fraction) > 2 || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	units, _ := strconv.ParseInt(whole, 10, 64)
+	cents := int64(0)
+	if fraction != "" {
+		cents, _ = strconv.ParseInt(fraction+strings.Repeat("0", 2-len(fraction)), 10, 64)
+	}
+
+	amount := Money(units*moneyScale + cents)
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MoneyFromFloat округляет дробное значение (например, результат деления) до копеек.
+func MoneyFromFloat(amount float64) Money {
+	return Money(math.Round(amount * moneyScale))
+}
+
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/moneyScale, value%moneyScale)
+}
+
+// Float64 нужен только для долей и процентов, не для сумм.
+func (m Money) Float64() float64 {
+	return float64(m) / moneyScale
+}
+
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Percent возвращает percent процентов суммы, округлённые до копеек.
+func (m Money) Percent(percent float64) Money {
+	return Money(math.Round(float64(m) * percent / 100))
+}
+
+// Ratio возвращает отношение m к total; при нулевом total — 0.
+func (m Money) Ratio(total Money) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(m) / float64(total)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + m.String() + `"`), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	amount, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// Scan читает NUMERIC из базы без промежуточного float64.
+func (m *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case string:
+		return m.scanText(value)
+	case []byte:
+		return m.scanText(string(value))
+	case int64:
+		*m = Money(value * moneyScale)
+		return nil
+	case nil:
+		*m = 0
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+}
+
+func (m *Money) scanText(text string) error {
+	amount, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "1234.56", want: 123456},
+		{input: "100", want: 10000},
+		{input: "0.5", want: 50},
+		{input: "-12.30", want: -1230},
+		{input: "7.000", want: 700},
+		{input: "0.001", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "12.3.4", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseMoney(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		amount Money
+		want   string
+	}{
+		{amount: 123456, want: "1234.56"},
+		{amount: 5, want: "0.05"},
+		{amount: -1230, want: "-12.30"},
+		{amount: 0, want: "0.00"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.want, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.amount.String(); got != tc.want {
+				t.Errorf("Expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Parallel()
+
+	var payload struct {
+		Number Money `json:"number"`
+		Text   Money `json:"text"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"number": 0.1, "text": "0.2"}`), &payload); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum := payload.Number + payload.Text; sum != 30 {
+		t.Errorf("Expected exact sum 30, got %d", sum)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != `{"number":"0.10","text":"0.20"}` {
+		t.Errorf("Expected string amounts, got %s", data)
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	t.Parallel()
+
+	var amount Money
+	if err := amount.Scan("98765432101.99"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if amount != 9876543210199 {
+		t.Errorf("Expected 9876543210199, got %d", amount)
+	}
+}
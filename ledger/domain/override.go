@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Виды временного изменения лимита.
+const (
+	OverrideReplace   = "replace"   // лимит заменяется на amount
+	OverrideIncrement = "increment" // к лимиту добавляется amount
+)
+
+// LimitOverride — временное изменение лимита бюджета с StartsOn по EndsOn включительно.
+// После EndsOn действует обычный лимит.
+type LimitOverride struct {
+	ID        int
+	BudgetID  int
+	Category  string
+	Period    string
+	Kind      string
+	Amount    Money
+	StartsOn  time.Time
+	EndsOn    time.Time
+	Reason    string
+	CreatedAt time.Time
+}
+
+func (o LimitOverride) Validate() error {
+	if o.Kind != OverrideReplace && o.Kind != OverrideIncrement {
+		return errors.New("вид изменения лимита должен быть 'replace' или 'increment'")
+	}
+	if o.Amount <= 0 {
+		return errors.New("сумма изменения лимита должна быть положительной")
+	}
+	if o.StartsOn.IsZero() || o.EndsOn.IsZero() {
+		return errors.New("даты начала и окончания изменения лимита обязательны")
+	}
+	if o.EndsOn.Before(o.StartsOn) {
+		return errors.New("дата окончания изменения лимита раньше даты начала")
+	}
+	return nil
+}
+
+// ActiveOn сообщает, действует ли изменение в день, содержащий момент at.
+func (o LimitOverride) ActiveOn(at time.Time) bool {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	starts := time.Date(o.StartsOn.Year(), o.StartsOn.Month(), o.StartsOn.Day(), 0, 0, 0, 0, time.UTC)
+	ends := time.Date(o.EndsOn.Year(), o.EndsOn.Month(), o.EndsOn.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(starts) && !day.After(ends)
+}
+
+// ApplyLimitOverrides применяет действующие изменения к лимиту: из замен берётся
+// последняя созданная, затем добавляются все увеличения.
+func ApplyLimitOverrides(limit Money, overrides []LimitOverride) Money {
+	var replaced *LimitOverride
+	for i, override := range overrides {
+		if override.Kind != OverrideReplace {
+			continue
+		}
+		if replaced == nil || override.CreatedAt.After(replaced.CreatedAt) ||
+			(override.CreatedAt.Equal(replaced.CreatedAt) && override.ID > replaced.ID) {
+			replaced = &overrides[i]
+		}
+	}
+	if replaced != nil {
+		limit = replaced.Amount
+	}
+
+	for _, override := range overrides {
+		if override.Kind == OverrideIncrement {
+			limit += override.Amount
+		}
+	}
+
+	return limit
+}
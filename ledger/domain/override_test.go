@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyLimitOverrides(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		overrides []LimitOverride
+		want      Money
+	}{
+		{name: "no overrides", overrides: nil, want: 1000},
+		{
+			name:      "replacement",
+			overrides: []LimitOverride{{ID: 1, Kind: OverrideReplace, Amount: 3000, CreatedAt: created}},
+			want:      3000,
+		},
+		{
+			name: "increments add up",
+			overrides: []LimitOverride{
+				{ID: 1, Kind: OverrideIncrement, Amount: 200, CreatedAt: created},
+				{ID: 2, Kind: OverrideIncrement, Amount: 300, CreatedAt: created},
+			},
+			want: 1500,
+		},
+		{
+			name: "latest replacement wins and increments apply on top",
+			overrides: []LimitOverride{
+				{ID: 2, Kind: OverrideReplace, Amount: 5000, CreatedAt: created.Add(time.Hour)},
+				{ID: 1, Kind: OverrideReplace, Amount: 3000, CreatedAt: created},
+				{ID: 3, Kind: OverrideIncrement, Amount: 500, CreatedAt: created},
+			},
+			want: 5500,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ApplyLimitOverrides(1000, tc.overrides)
+			if got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLimitOverrideActiveOn(t *testing.T) {
+	t.Parallel()
+
+	override := LimitOverride{
+		StartsOn: time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC),
+		EndsOn:   time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "before start", at: time.Date(2024, 12, 19, 23, 0, 0, 0, time.UTC), want: false},
+		{name: "first day", at: time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC), want: true},
+		{name: "last day evening", at: time.Date(2025, 1, 10, 21, 30, 0, 0, time.UTC), want: true},
+		{name: "expired", at: time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := override.ActiveOn(tc.at); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
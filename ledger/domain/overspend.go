@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Политики превышения бюджета.
+const (
+	OverspendBlock    = "block"    // отклонить транзакцию
+	OverspendAllow    = "allow"    // принять и пометить как превышение
+	OverspendOverride = "override" // принять только с явным подтверждением и причиной
+)
+
+var ErrOverrideRequired = errors.New("budget override required")
+
+// DefaultOverspendReason записывается для превышений, принятых по политике allow без причины.
+const DefaultOverspendReason = "allowed by budget policy"
+
+// ValidateOverspendPolicy проверяет политику превышения; пустая означает block.
+func ValidateOverspendPolicy(policy string) error {
+	switch policy {
+	case "", OverspendBlock, OverspendAllow, OverspendOverride:
+		return nil
+	}
+	return errors.New("overspend policy must be 'block', 'allow' or 'override'")
+}
+
+// Overspend — превышение лимита бюджета, с которым транзакция всё же была принята.
+type Overspend struct {
+	BudgetCategory string
+	Policy         string
+	Reason         string
+	Limit          Money
+	Spent          Money
+}
+
+// OverspendRecord — сохранённое превышение вместе с транзакцией, которая его вызвала.
+type OverspendRecord struct {
+	Overspend
+	Transaction Transaction
+	CreatedAt   time.Time
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestValidateOverspendPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{policy: "", wantErr: false},
+		{policy: OverspendBlock, wantErr: false},
+		{policy: OverspendAllow, wantErr: false},
+		{policy: OverspendOverride, wantErr: false},
+		{policy: "warn", wantErr: true},
+		{policy: "Block", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.policy, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateOverspendPolicy(tc.policy)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+)
+
+const periodRangeSeparator = ".."
+
+// IsRecurringPeriod сообщает, повторяется ли период (день/неделя/месяц),
+// в отличие от календарных периодов вида "2024-01", "2024-W05" или "2024-01-01..2024-01-15".
+func IsRecurringPeriod(period string) bool {
+	switch period {
+	case "", PeriodDaily, PeriodWeekly, PeriodMonthly:
+		return true
+	}
+	return false
+}
+
+// ValidatePeriod проверяет, что период повторяющийся или корректный календарный.
+func ValidatePeriod(period string) error {
+	if IsRecurringPeriod(period) {
+		return nil
+	}
+	_, _, err := ParseCalendarPeriod(period)
+	return err
+}
+
+// ParseCalendarPeriod возвращает первый и последний день календарного периода:
+// месяца ("2024-01"), ISO-недели ("2024-W05") или явного диапазона ("2024-01-01..2024-01-15").
+func ParseCalendarPeriod(period string) (time.Time, time.Time, error) {
+	if start, end, found := strings.Cut(period, periodRangeSeparator); found {
+		from, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid range start: %s", start)
+		}
+		to, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid range end: %s", end)
+		}
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, errors.New("range end is before range start")
+		}
+		return from, to, nil
+	}
+
+	if year, week, found := strings.Cut(period, "-W"); found {
+		var y, w int
+		if _, err := fmt.Sscanf(year+" "+week, "%4d %2d", &y, &w); err != nil || len(year) != 4 || len(week) != 2 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid ISO week: %s", period)
+		}
+		from := isoWeekStart(y, w)
+		if fy, fw := from.ISOWeek(); fy != y || fw != w {
+			return time.Time{}, time.Time{}, fmt.Errorf("week %d does not exist in %d", w, y)
+		}
+		return from, from.AddDate(0, 0, 6), nil
+	}
+
+	month, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period: %s", period)
+	}
+	return month, month.AddDate(0, 1, -1), nil
+}
+
+func isoWeekStart(year, week int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, (week-1)*7)
+}
+
+// PeriodRange возвращает границы (включительно) периода, в который попадает момент at.
+// Пустой период трактуется как месячный, неделя начинается с понедельника.
+// Для календарных периодов at не учитывается.
+func PeriodRange(period string, at time.Time) (time.Time, time.Time) {
+	if !IsRecurringPeriod(period) {
+		if first, last, err := ParseCalendarPeriod(period); err == nil {
+			from := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, at.Location())
+			to := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, at.Location())
+			return from, to.AddDate(0, 0, 1).Add(-time.Second)
+		}
+	}
+
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+
+	var from, next time.Time
+	switch period {
+	case PeriodDaily:
+		from = day
+		next = from.AddDate(0, 0, 1)
+	case PeriodWeekly:
+		offset := (int(day.Weekday()) + 6) % 7
+		from = day.AddDate(0, 0, -offset)
+		next = from.AddDate(0, 0, 7)
+	default:
+		from = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, at.Location())
+		next = from.AddDate(0, 1, 0)
+	}
+
+	return from, next.Add(-time.Second)
+}
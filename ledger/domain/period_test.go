@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodRange(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 1, 17, 15, 30, 0, 0, time.UTC) // среда
+
+	testCases := []struct {
+		name     string
+		period   string
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{
+			name:     "daily",
+			period:   PeriodDaily,
+			wantFrom: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 1, 17, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:     "weekly starts on monday",
+			period:   PeriodWeekly,
+			wantFrom: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 1, 21, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:     "monthly",
+			period:   PeriodMonthly,
+			wantFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:     "empty period is monthly",
+			period:   "",
+			wantFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			from, to := PeriodRange(tc.period, at)
+			if !from.Equal(tc.wantFrom) || !to.Equal(tc.wantTo) {
+				t.Errorf("Expected [%v, %v], got [%v, %v]", tc.wantFrom, tc.wantTo, from, to)
+			}
+		})
+	}
+}
+
+func TestPeriodRangeWeeklyOnSunday(t *testing.T) {
+	t.Parallel()
+
+	from, to := PeriodRange(PeriodWeekly, time.Date(2024, 1, 21, 10, 0, 0, 0, time.UTC))
+
+	if want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("Expected week start %v, got %v", want, from)
+	}
+	if want := time.Date(2024, 1, 21, 23, 59, 59, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("Expected week end %v, got %v", want, to)
+	}
+}
+
+func TestParseCalendarPeriod(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		period      string
+		wantFrom    time.Time
+		wantTo      time.Time
+		shouldError bool
+	}{
+		{
+			name:     "month",
+			period:   "2024-02",
+			wantFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "iso week",
+			period:   "2024-W05",
+			wantFrom: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "iso week 1 starting in previous year",
+			period:   "2025-W01",
+			wantFrom: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "explicit range",
+			period:   "2024-01-10..2024-01-20",
+			wantFrom: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "reversed range",
+			period:      "2024-01-20..2024-01-10",
+			shouldError: true,
+		},
+		{
+			name:        "week 53 in a 52-week year",
+			period:      "2023-W53",
+			shouldError: true,
+		},
+		{
+			name:        "garbage",
+			period:      "yearly",
+			shouldError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			from, to, err := ParseCalendarPeriod(tc.period)
+			if tc.shouldError {
+				if err == nil {
+					t.Errorf("Expected error, got [%v, %v]", from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got '%s'", err)
+			}
+			if !from.Equal(tc.wantFrom) || !to.Equal(tc.wantTo) {
+				t.Errorf("Expected [%v, %v], got [%v, %v]", tc.wantFrom, tc.wantTo, from, to)
+			}
+		})
+	}
+}
+
+func TestPeriodRangeCalendarIgnoresDate(t *testing.T) {
+	t.Parallel()
+
+	from, to := PeriodRange("2024-01", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC))
+
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, from)
+	}
+	if want := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, to)
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BudgetPool — общий лимит на несколько категорий (например, "Развлечения", "Кафе" и "Хобби").
+// Расходы подкатегорий участников тоже учитываются.
+type BudgetPool struct {
+	ID         int
+	Name       string
+	Categories []string
+	Limit      Money
+	Period     string
+}
+
+func (p BudgetPool) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("название общего бюджета не может быть пустым")
+	}
+	if len(p.Categories) == 0 {
+		return errors.New("общий бюджет должен включать хотя бы одну категорию")
+	}
+	for _, category := range p.Categories {
+		if strings.TrimSpace(category) == "" {
+			return errors.New("категория общего бюджета не может быть пустой")
+		}
+		if err := ValidateCategory(category); err != nil {
+			return err
+		}
+	}
+	if p.Limit <= 0 {
+		return errors.New("лимит общего бюджета должен быть положительным числом")
+	}
+	if err := ValidatePeriod(p.Period); err != nil {
+		return fmt.Errorf("некорректный период общего бюджета: %w", err)
+	}
+	return nil
+}
+
+// PeriodRange возвращает границы периода общего бюджета, в который попадает момент at.
+func (p BudgetPool) PeriodRange(at time.Time) (time.Time, time.Time) {
+	return PeriodRange(p.Period, at)
+}
+
+// PoolExceededError сообщает, какой общий бюджет превышен. Оборачивает ErrBudgetExceeded.
+type PoolExceededError struct {
+	Pool string
+}
+
+func (e *PoolExceededError) Error() string {
+	return fmt.Sprintf("budget pool %q exceeded", e.Pool)
+}
+
+func (e *PoolExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestBudgetPoolValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := BudgetPool{Name: "Досуг", Categories: []string{"Кафе", "Хобби/Книги"}, Limit: 500000, Period: PeriodMonthly}
+
+	testCases := []struct {
+		name    string
+		modify  func(p *BudgetPool)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(p *BudgetPool) {}},
+		{name: "empty name", modify: func(p *BudgetPool) { p.Name = "  " }, wantErr: true},
+		{name: "no categories", modify: func(p *BudgetPool) { p.Categories = nil }, wantErr: true},
+		{name: "empty category", modify: func(p *BudgetPool) { p.Categories = []string{"Кафе", ""} }, wantErr: true},
+		{name: "empty category level", modify: func(p *BudgetPool) { p.Categories = []string{"Хобби//Книги"} }, wantErr: true},
+		{name: "zero limit", modify: func(p *BudgetPool) { p.Limit = 0 }, wantErr: true},
+		{name: "bad period", modify: func(p *BudgetPool) { p.Period = "hourly" }, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pool := valid
+			pool.Categories = append([]string(nil), valid.Categories...)
+			tc.modify(&pool)
+
+			err := pool.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
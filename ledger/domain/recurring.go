@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Частота повторяющихся транзакций.
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"  // в день недели даты начала
+	FrequencyMonthly = "monthly" // в DayOfMonth или в число даты начала
+	FrequencyYearly  = "yearly"  // в день и месяц даты начала
+)
+
+// Статусы проведения повторяющейся транзакции.
+const (
+	OccurrencePending  = "pending"  // проведение начато, но не завершено; дата будет проведена заново
+	OccurrencePosted   = "posted"   // транзакция создана
+	OccurrenceRejected = "rejected" // транзакция отклонена бюджетом, проверкой или из-за отсутствия курса
+)
+
+var ErrRecurringNotFound = errors.New("recurring transaction not found")
+
+// RecurringTransaction — шаблон транзакции, которая проводится по расписанию
+// с StartsOn по EndsOn включительно.
+type RecurringTransaction struct {
+	ID          int
+	Type        string
+	Amount      Money // без знака, как в запросе на создание транзакции
+	Currency    string
+	Category    string
+	Description string
+	Tags        []string
+	Frequency   string
+	Interval    int // каждые Interval дней, недель, месяцев или лет
+	DayOfMonth  int // для monthly; 0 — число даты начала, 31 — последний день месяца
+	StartsOn    time.Time
+	EndsOn      *time.Time
+	Active      bool
+	CreatedAt   time.Time
+}
+
+func (r RecurringTransaction) Validate() error {
+	if ValidateTransactionType(r.Type) != nil {
+		return errors.New("тип транзакции должен быть 'expense', 'refund' или 'income'")
+	}
+	if r.Amount <= 0 {
+		return errors.New("сумма транзакции должна быть положительным числом")
+	}
+	if strings.TrimSpace(r.Category) == "" {
+		return errors.New("категория транзакции не может быть пустой")
+	}
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	default:
+		return errors.New("частота должна быть 'daily', 'weekly', 'monthly' или 'yearly'")
+	}
+	if r.Interval < 1 {
+		return errors.New("интервал должен быть не меньше 1")
+	}
+	if r.DayOfMonth < 0 || r.DayOfMonth > 31 {
+		return errors.New("день месяца должен быть в диапазоне 1..31")
+	}
+	if r.DayOfMonth != 0 && r.Frequency != FrequencyMonthly {
+		return errors.New("день месяца задаётся только для ежемесячных транзакций")
+	}
+	if r.StartsOn.IsZero() {
+		return errors.New("дата начала должна быть указана")
+	}
+	if r.EndsOn != nil && r.EndsOn.Before(r.StartsOn) {
+		return errors.New("дата окончания не может быть раньше даты начала")
+	}
+	return nil
+}
+
+// DueDates возвращает даты проведения в диапазоне [from, until] с учётом дат начала и окончания.
+// Если в месяце нет нужного числа, транзакция проводится в последний день месяца.
+func (r RecurringTransaction) DueDates(from, until time.Time) []time.Time {
+	start := dateOf(r.StartsOn)
+	from, until = dateOf(from), dateOf(until)
+	if r.EndsOn != nil && dateOf(*r.EndsOn).Before(until) {
+		until = dateOf(*r.EndsOn)
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var dates []time.Time
+	for k := 0; ; k++ {
+		due := r.nthDate(start, k*interval)
+		if due.After(until) {
+			return dates
+		}
+		if !due.Before(start) && !due.Before(from) {
+			dates = append(dates, due)
+		}
+	}
+}
+
+// nthDate возвращает дату, отстоящую от start на n дней, недель, месяцев или лет.
+func (r RecurringTransaction) nthDate(start time.Time, n int) time.Time {
+	switch r.Frequency {
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		day := r.DayOfMonth
+		if day == 0 {
+			day = start.Day()
+		}
+		return clampedDate(start.Year(), start.Month()+time.Month(n), day)
+	case FrequencyYearly:
+		return clampedDate(start.Year()+n, start.Month(), start.Day())
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// clampedDate возвращает дату с числом day или последним днём месяца, если он короче.
+// Месяц вне 1..12 переносится на соседние годы.
+func clampedDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// dateOf отбрасывает время, оставляя календарную дату в UTC.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Transaction возвращает запрос на создание транзакции для проведения в дату due.
+func (r RecurringTransaction) Transaction(due time.Time) CreateTransactionRequest {
+	return CreateTransactionRequest{
+		Type:        r.Type,
+		Amount:      r.Amount,
+		Currency:    r.Currency,
+		Category:    r.Category,
+		Description: r.Description,
+		Date:        due,
+		Tags:        r.Tags,
+	}
+}
+
+// Occurrence — проведение повторяющейся транзакции в дату DueOn. Для каждой даты
+// проведение создаётся не больше одного раза.
+type Occurrence struct {
+	ID            int
+	RecurringID   int
+	DueOn         time.Time
+	Status        string
+	TransactionID *int
+	Error         string // причина отклонения
+	CreatedAt     time.Time
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRecurringDueDates(t *testing.T) {
+	t.Parallel()
+
+	endsOn := date(2024, 3, 31)
+
+	testCases := []struct {
+		name      string
+		recurring RecurringTransaction
+		from      time.Time
+		until     time.Time
+		want      []time.Time
+	}{
+		{
+			name:      "monthly on start day",
+			recurring: RecurringTransaction{Frequency: FrequencyMonthly, Interval: 1, StartsOn: date(2024, 1, 5)},
+			from:      date(2024, 1, 1),
+			until:     date(2024, 3, 10),
+			want:      []time.Time{date(2024, 1, 5), date(2024, 2, 5), date(2024, 3, 5)},
+		},
+		{
+			name:      "monthly on day 31 clamps to month end",
+			recurring: RecurringTransaction{Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 31, StartsOn: date(2024, 1, 10)},
+			from:      date(2024, 1, 1),
+			until:     date(2024, 4, 30),
+			want:      []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30)},
+		},
+		{
+			name:      "monthly day before start skips first month",
+			recurring: RecurringTransaction{Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 1, StartsOn: date(2024, 1, 15)},
+			from:      date(2024, 1, 1),
+			until:     date(2024, 3, 1),
+			want:      []time.Time{date(2024, 2, 1), date(2024, 3, 1)},
+		},
+		{
+			name:      "every two weeks",
+			recurring: RecurringTransaction{Frequency: FrequencyWeekly, Interval: 2, StartsOn: date(2024, 1, 1)},
+			from:      date(2024, 1, 1),
+			until:     date(2024, 2, 1),
+			want:      []time.Time{date(2024, 1, 1), date(2024, 1, 15), date(2024, 1, 29)},
+		},
+		{
+			name:      "daily from catch up date",
+			recurring: RecurringTransaction{Frequency: FrequencyDaily, Interval: 1, StartsOn: date(2024, 1, 1)},
+			from:      date(2024, 1, 30),
+			until:     date(2024, 2, 1),
+			want:      []time.Time{date(2024, 1, 30), date(2024, 1, 31), date(2024, 2, 1)},
+		},
+		{
+			name:      "yearly on leap day",
+			recurring: RecurringTransaction{Frequency: FrequencyYearly, Interval: 1, StartsOn: date(2024, 2, 29)},
+			from:      date(2024, 1, 1),
+			until:     date(2025, 12, 31),
+			want:      []time.Time{date(2024, 2, 29), date(2025, 2, 28)},
+		},
+		{
+			name:      "stops at end date",
+			recurring: RecurringTransaction{Frequency: FrequencyMonthly, Interval: 1, StartsOn: date(2024, 1, 31), EndsOn: &endsOn},
+			from:      date(2024, 1, 1),
+			until:     date(2024, 12, 31),
+			want:      []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31)},
+		},
+		{
+			name:      "not started yet",
+			recurring: RecurringTransaction{Frequency: FrequencyDaily, Interval: 1, StartsOn: date(2024, 6, 1)},
+			from:      date(2024, 1, 1),
+			until:     date(2024, 5, 31),
+			want:      nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.recurring.DueDates(tc.from, tc.until)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Expected %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				if !got[i].Equal(tc.want[i]) {
+					t.Errorf("Expected %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRecurringValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := RecurringTransaction{
+		Type:      TransactionExpense,
+		Amount:    4500000,
+		Category:  "Аренда",
+		Frequency: FrequencyMonthly,
+		Interval:  1,
+		StartsOn:  date(2024, 1, 1),
+	}
+	before := date(2023, 12, 31)
+
+	testCases := []struct {
+		name    string
+		modify  func(r *RecurringTransaction)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(r *RecurringTransaction) {}},
+		{name: "unknown frequency", modify: func(r *RecurringTransaction) { r.Frequency = "hourly" }, wantErr: true},
+		{name: "zero interval", modify: func(r *RecurringTransaction) { r.Interval = 0 }, wantErr: true},
+		{name: "day of month for weekly", modify: func(r *RecurringTransaction) { r.Frequency = FrequencyWeekly; r.DayOfMonth = 5 }, wantErr: true},
+		{name: "day of month out of range", modify: func(r *RecurringTransaction) { r.DayOfMonth = 32 }, wantErr: true},
+		{name: "ends before start", modify: func(r *RecurringTransaction) { r.EndsOn = &before }, wantErr: true},
+		{name: "no amount", modify: func(r *RecurringTransaction) { r.Amount = 0 }, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			recurring := valid
+			tc.modify(&recurring)
+
+			err := recurring.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
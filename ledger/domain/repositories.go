@@ -2,21 +2,171 @@ package domain
 
 import (
 	"context"
+	"io"
+	"math/big"
 	"time"
 )
 
+// Transactor выполняет операции нескольких репозиториев атомарно.
+type Transactor interface {
+	// WithinTransaction выполняет fn в транзакции БД: репозитории, вызванные с ctx из fn,
+	// работают в ней. При конфликте сериализации или взаимной блокировке fn выполняется заново.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Lock берёт блокировки по ключам до конца транзакции; вызывается внутри WithinTransaction.
+	Lock(ctx context.Context, keys ...string) error
+}
+
 type TransactionRepository interface {
-	Create(ctx context.Context, transaction Transaction) (int, error)
-	List(ctx context.Context) ([]Transaction, error)
-	GetTotalByCategory(ctx context.Context, category string) (float64, error)
+	// Create сохраняет транзакцию и записи о превышении бюджетов в одной транзакции БД.
+	Create(ctx context.Context, transaction Transaction, overspends ...Overspend) (int, error)
+	List(ctx context.Context, filter TransactionFilter) ([]Transaction, error)
+	// Categories возвращает категории, в которых есть неудалённые транзакции.
+	Categories(ctx context.Context) ([]string, error)
+	// GetTotalByCategory считает все расходы категории в базовой валюте.
+	GetTotalByCategory(ctx context.Context, category string) (Money, error)
 	GetByID(ctx context.Context, id int) (*Transaction, error)
-	GetSpendingByPeriod(ctx context.Context, from, to time.Time) (SpendingSummary, error)
-	GetSpendingByCategoryAndPeriod(ctx context.Context, category string, from, to time.Time) (float64, error) // ДОБАВЛЕН
+	// GetForUpdate возвращает транзакцию или nil и блокирует её до конца транзакции БД.
+	GetForUpdate(ctx context.Context, id int) (*Transaction, error)
+	// Search ищет транзакции по описанию и возвращает их по убыванию релевантности.
+	Search(ctx context.Context, search TransactionSearch) ([]SearchResult, error)
+	Update(ctx context.Context, transaction Transaction) error
+	// Delete помечает транзакцию удалённой, она перестаёт учитываться в расходах.
+	Delete(ctx context.Context, id int) error
+	// Reverse сохраняет сторнирующую запись для транзакции reversal.ReversesID.
+	Reverse(ctx context.Context, reversal Transaction) (int, error)
+	// CreateSplit сохраняет платёж и все его строки в одной транзакции БД и заполняет их ID.
+	CreateSplit(ctx context.Context, split *Split) error
+	// GetSplit возвращает платёж с неудалёнными строками или nil, если его нет.
+	GetSplit(ctx context.Context, id int) (*Split, error)
+	// DeleteSplit помечает удалёнными все строки платежа.
+	DeleteSplit(ctx context.Context, id int) error
+	// Changes возвращает журнал изменений транзакции от создания.
+	Changes(ctx context.Context, id int) ([]TransactionChange, error)
+	// Суммы расходов ниже пересчитываются в валюту currency по курсу на дату каждой транзакции;
+	// если курса нет, возвращается ошибка ErrExchangeRateNotFound.
+	GetSpendingByPeriod(ctx context.Context, from, to time.Time, currency string) (SpendingSummary, error)
+	// GetSpendingByCategoryAndPeriod при непустом tags учитывает только транзакции хотя бы с одной из меток.
+	GetSpendingByCategoryAndPeriod(ctx context.Context, category string, from, to time.Time, currency string, tags []string) (Money, error)
+	// GetSpendingByCategoryTreeAndPeriod считает расходы категории вместе со всеми её потомками.
+	GetSpendingByCategoryTreeAndPeriod(ctx context.Context, category string, from, to time.Time, currency string) (Money, error)
+	// GetSpendingByCategoriesAndPeriod считает расходы нескольких категорий и их потомков без двойного учёта.
+	GetSpendingByCategoriesAndPeriod(ctx context.Context, categories []string, from, to time.Time, currency string) (Money, error)
+	// GetSpendingByPeriods считает собственные расходы категорий отдельно за каждый день, неделю или месяц.
+	GetSpendingByPeriods(ctx context.Context, categories []string, period string, from, to time.Time, currency string) ([]PeriodSpending, error)
+	// GetSpendingByTags считает расходы по меткам; при непустом tags — только по этим меткам.
+	GetSpendingByTags(ctx context.Context, from, to time.Time, currency string, tags []string) ([]TagSpending, error)
+	// GetCashFlow суммирует доходы, расходы и возвраты за период.
+	GetCashFlow(ctx context.Context, from, to time.Time, currency string) (CashFlow, error)
 }
 
 type BudgetRepository interface {
-	Save(ctx context.Context, budget Budget) error
-	GetByCategory(ctx context.Context, category string) (*Budget, error)
+	Save(ctx context.Context, budget *Budget) error
+	// GetByCategory возвращает бюджет категории, действующий на дату date:
+	// календарный бюджет, содержащий дату, важнее повторяющегося. Limit — по версии на эту дату.
+	GetByCategory(ctx context.Context, category string, date time.Time) (*Budget, error)
 	List(ctx context.Context) ([]Budget, error)
 	Exists(ctx context.Context, category string) (bool, error)
+	// LimitAt возвращает лимит бюджета по версии, действующей на дату date.
+	LimitAt(ctx context.Context, budgetID int, date time.Time) (Money, error)
+	// History возвращает все версии лимитов бюджетов категории.
+	History(ctx context.Context, category string) ([]BudgetVersion, error)
+	SaveOverride(ctx context.Context, override *LimitOverride) error
+	// OverridesAt возвращает временные изменения лимита бюджета, действующие на дату date.
+	OverridesAt(ctx context.Context, budgetID int, date time.Time) ([]LimitOverride, error)
+	// Overrides возвращает все временные изменения лимитов бюджетов категории, включая истёкшие.
+	Overrides(ctx context.Context, category string) ([]LimitOverride, error)
+}
+
+type CategoryRepository interface {
+	// Ensure создаёт категорию и недостающих предков по её пути.
+	Ensure(ctx context.Context, name string) error
+	List(ctx context.Context) ([]Category, error)
+}
+
+type BudgetPoolRepository interface {
+	// Save создаёт или обновляет общий бюджет по имени, заменяя список категорий.
+	Save(ctx context.Context, pool *BudgetPool) error
+	List(ctx context.Context) ([]BudgetPool, error)
+	// ListByCategories возвращает общие бюджеты, включающие любую из категорий и действующие на дату date.
+	ListByCategories(ctx context.Context, categories []string, date time.Time) ([]BudgetPool, error)
+}
+
+type OverspendRepository interface {
+	Record(ctx context.Context, transactionID int, overspends []Overspend) error
+	// Replace заменяет все записи о превышении транзакции на overspends.
+	Replace(ctx context.Context, transactionID int, overspends []Overspend) error
+	List(ctx context.Context, from, to time.Time) ([]OverspendRecord, error)
+}
+
+type ExchangeRateRepository interface {
+	// Save добавляет курс или заменяет курс той же пары на ту же дату.
+	Save(ctx context.Context, rate ExchangeRate) error
+	List(ctx context.Context, currency, baseCurrency string) ([]ExchangeRate, error)
+	// Rate возвращает последний на дату date курс from к to, прямой или обратный;
+	// курс точный, как NUMERIC в базе. Если курса нет, возвращается ошибка ErrExchangeRateNotFound.
+	Rate(ctx context.Context, from, to string, date time.Time) (*big.Rat, error)
+}
+
+type RecurringRepository interface {
+	Create(ctx context.Context, recurring *RecurringTransaction) error
+	List(ctx context.Context) ([]RecurringTransaction, error)
+	// Active возвращает действующие шаблоны.
+	Active(ctx context.Context) ([]RecurringTransaction, error)
+	// Deactivate останавливает проведение шаблона; история проведений сохраняется.
+	Deactivate(ctx context.Context, id int) error
+	// OccurrenceDates возвращает даты, проведение которых завершено.
+	OccurrenceDates(ctx context.Context, recurringID int) ([]time.Time, error)
+	// ClaimOccurrence создаёт проведение в статусе pending или занимает оставшееся
+	// незавершённым; false, если дата уже проведена. Вызывается внутри WithinTransaction,
+	// чтобы проведение завершилось в той же транзакции БД.
+	ClaimOccurrence(ctx context.Context, recurringID int, dueOn time.Time) (int, bool, error)
+	// CompleteOccurrence сохраняет итог проведения: статус, транзакцию или причину отклонения.
+	CompleteOccurrence(ctx context.Context, occurrence Occurrence) error
+	// Occurrences возвращает проведения шаблона (recurringID 0 — всех шаблонов),
+	// при непустом status — только с этим статусом.
+	Occurrences(ctx context.Context, recurringID int, status string) ([]Occurrence, error)
+}
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *Attachment) error
+	// List возвращает вложения транзакции в порядке загрузки.
+	List(ctx context.Context, transactionID int) ([]Attachment, error)
+	// Get возвращает вложение транзакции или nil, если его нет.
+	Get(ctx context.Context, transactionID, id int) (*Attachment, error)
+	Delete(ctx context.Context, id int) error
+	// References считает вложения с содержимым sha256.
+	References(ctx context.Context, sha256 string) (int, error)
+}
+
+type IdempotencyRepository interface {
+	// Claim занимает ключ record.Key под выполняемый запрос. Если ключ уже занят,
+	// возвращает существующую запись и false. Истёкшие ключи, а также незавершённые
+	// дольше срока аренды считаются свободными.
+	Claim(ctx context.Context, record IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	// Complete сохраняет ответ на запрос с ключом key.
+	Complete(ctx context.Context, key string, response StoredResponse) error
+	// Release освобождает ключ незавершённого запроса, чтобы его можно было повторить.
+	Release(ctx context.Context, key string) error
+}
+
+// BlobStore хранит содержимое по его хешу SHA-256.
+type BlobStore interface {
+	// Stage записывает содержимое во временную копию и считает его хеш и размер.
+	// В хранилище содержимое попадает только после StagedBlob.Commit.
+	Stage(ctx context.Context, content io.Reader) (StagedBlob, error)
+	// Open возвращает содержимое или ErrBlobNotFound.
+	Open(ctx context.Context, sha256 string) (io.ReadCloser, error)
+	// Delete удаляет содержимое; отсутствие содержимого не считается ошибкой.
+	Delete(ctx context.Context, sha256 string) error
+}
+
+// StagedBlob — записанное, но ещё не сохранённое в хранилище содержимое.
+type StagedBlob interface {
+	SHA256() string
+	Size() int64
+	// Commit сохраняет содержимое в хранилище; если такое содержимое уже есть, копия
+	// не создаётся. Повторный вызов безопасен.
+	Commit(ctx context.Context) error
+	// Discard удаляет временную копию; сохранённое через Commit содержимое остаётся.
+	Discard() error
 }
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSearchQueryLength — наибольшая длина поискового запроса в символах.
+const MaxSearchQueryLength = 200
+
+// TransactionSearch — полнотекстовый поиск по описаниям транзакций. Query разбирается
+// как веб-запрос: слова через пробел, "фраза в кавычках", or, -исключение.
+type TransactionSearch struct {
+	Query    string
+	Category string     // категория вместе с подкатегориями
+	From     *time.Time // включительно
+	To       *time.Time // включительно
+	Limit    int
+}
+
+func (s TransactionSearch) Validate() error {
+	query := strings.TrimSpace(s.Query)
+	if query == "" {
+		return errors.New("search query is required")
+	}
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return errors.New("search query cannot be longer than 200 characters")
+	}
+	if s.Limit < 1 || s.Limit > MaxPageSize {
+		return errors.New("limit must be between 1 and 500")
+	}
+	if s.From != nil && s.To != nil && s.From.After(*s.To) {
+		return errors.New("from date cannot be after to date")
+	}
+	return nil
+}
+
+// SearchResult — найденная транзакция с релевантностью и описанием, экранированным
+// для HTML, в котором совпадения выделены тегами <b></b>.
+type SearchResult struct {
+	Transaction Transaction
+	Rank        float64
+	Headline    string
+}
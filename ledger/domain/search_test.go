@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransactionSearchValidate(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		request SearchTransactionsRequest
+		wantErr bool
+	}{
+		{name: "valid", request: SearchTransactionsRequest{Query: "такси аэропорт"}},
+		{name: "empty query", request: SearchTransactionsRequest{Query: "   "}, wantErr: true},
+		{name: "long query", request: SearchTransactionsRequest{Query: strings.Repeat("а", MaxSearchQueryLength+1)}, wantErr: true},
+		{name: "limit too large", request: SearchTransactionsRequest{Query: "такси", Limit: MaxPageSize + 1}, wantErr: true},
+		{name: "from after to", request: SearchTransactionsRequest{Query: "такси", From: &from, To: &to}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.request.ToSearch().Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -3,11 +3,14 @@ package domain
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
 	ErrBudgetNotFound = errors.New("budget not found")
 	ErrBudgetExceeded = errors.New("budget exceeded")
+	// ErrValidation оборачивает ошибки проверки запроса; текст ошибки — "validation failed: <причина>".
+	ErrValidation = errors.New("validation failed")
 )
 
 type BudgetService struct {
@@ -22,8 +25,9 @@ func NewBudgetService(budgetRepo BudgetRepository, transRepo TransactionReposito
 	}
 }
 
-func (s *BudgetService) CanSpend(ctx context.Context, category string, amount float64) (bool, error) {
-	budget, err := s.budgetRepo.GetByCategory(ctx, category)
+// CanSpend проверяет, укладывается ли сумма amount в валюте бюджета в его лимит.
+func (s *BudgetService) CanSpend(ctx context.Context, category string, amount Money, date time.Time) (bool, error) {
+	budget, err := s.budgetRepo.GetByCategory(ctx, category, date)
 	if err != nil {
 		return false, err
 	}
@@ -31,33 +35,90 @@ func (s *BudgetService) CanSpend(ctx context.Context, category string, amount fl
 		return false, ErrBudgetNotFound
 	}
 
-	spent, err := s.transRepo.GetTotalByCategory(ctx, category)
+	limit, err := s.EffectiveLimit(ctx, *budget, date)
 	if err != nil {
 		return false, err
 	}
 
-	return spent+amount <= budget.Limit, nil
+	from, to := budget.PeriodRange(date)
+
+	spent, err := s.transRepo.GetSpendingByCategoryTreeAndPeriod(ctx, category, from, to, budget.Currency)
+	if err != nil {
+		return false, err
+	}
+
+	return spent+amount <= limit, nil
 }
 
-func (s *BudgetService) GetRemainingBudget(ctx context.Context, category string) (float64, error) {
-	budget, err := s.budgetRepo.GetByCategory(ctx, category)
+func (s *BudgetService) GetRemainingBudget(ctx context.Context, category string) (Money, error) {
+	status, err := s.GetBudgetStatus(ctx, category, time.Now())
 	if err != nil {
 		return 0, err
 	}
+	return status.Remaining, nil
+}
+
+// GetBudgetStatus возвращает состояние бюджета категории, действующего на момент at.
+func (s *BudgetService) GetBudgetStatus(ctx context.Context, category string, at time.Time) (*BudgetStatus, error) {
+	budget, err := s.budgetRepo.GetByCategory(ctx, category, at)
+	if err != nil {
+		return nil, err
+	}
 	if budget == nil {
-		return 0, ErrBudgetNotFound
+		return nil, ErrBudgetNotFound
+	}
+
+	return s.StatusOf(ctx, *budget, at)
+}
+
+// StatusOf считает лимит, расход и остаток бюджета в периоде, содержащем момент at.
+func (s *BudgetService) StatusOf(ctx context.Context, budget Budget, at time.Time) (*BudgetStatus, error) {
+	limit, err := s.EffectiveLimit(ctx, budget, at)
+	if err != nil {
+		return nil, err
 	}
 
-	spent, err := s.transRepo.GetTotalByCategory(ctx, category)
+	from, to := budget.PeriodRange(at)
+
+	spent, err := s.transRepo.GetSpendingByCategoryTreeAndPeriod(ctx, budget.Category, from, to, budget.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBudgetStatus(budget, limit, spent, from, to, at), nil
+}
+
+// EffectiveLimit возвращает лимит бюджета для периода с моментом at с учётом временных
+// изменений, действующих на at, и переноса. Переносится результат только одного предыдущего
+// периода и только если бюджет тогда уже существовал; временные изменения прошлого периода
+// в перенос не попадают.
+func (s *BudgetService) EffectiveLimit(ctx context.Context, budget Budget, at time.Time) (Money, error) {
+	overrides, err := s.budgetRepo.OverridesAt(ctx, budget.ID, at)
 	if err != nil {
 		return 0, err
 	}
+	budget.Limit = ApplyLimitOverrides(budget.Limit, overrides)
 
-	remaining := budget.Limit - spent
-	if remaining < 0 {
-		return 0, nil
+	if !budget.Rollover {
+		return budget.Limit, nil
 	}
-	return remaining, nil
+
+	from, to := budget.PreviousPeriodRange(at)
+	if budget.CreatedAt.After(to) {
+		return budget.Limit, nil
+	}
+
+	previousLimit, err := s.budgetRepo.LimitAt(ctx, budget.ID, from)
+	if err != nil {
+		return 0, err
+	}
+
+	spent, err := s.transRepo.GetSpendingByCategoryTreeAndPeriod(ctx, budget.Category, from, to, budget.Currency)
+	if err != nil {
+		return 0, err
+	}
+
+	return budget.EffectiveLimit(previousLimit, spent), nil
 }
 
 type TransactionService struct {
@@ -77,7 +138,7 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, transaction
 		return 0, err
 	}
 
-	canSpend, err := s.budgetService.CanSpend(ctx, transaction.Category, transaction.Amount)
+	canSpend, err := s.budgetService.CanSpend(ctx, transaction.Category, transaction.Amount, transaction.Date)
 	if err != nil {
 		return 0, err
 	}
@@ -89,5 +150,5 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, transaction
 }
 
 func (s *TransactionService) GetTransactionHistory(ctx context.Context) ([]Transaction, error) {
-	return s.transRepo.List(ctx)
+	return s.transRepo.List(ctx, TransactionFilter{})
 }
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSplitNotFound = errors.New("split not found")
+	ErrSplitLine     = errors.New("transaction is a split line")
+)
+
+// Split — один платёж, разнесённый по нескольким категориям. Каждая строка хранится
+// как отдельная транзакция-расход со ссылкой на платёж, поэтому в бюджетах и отчётах
+// строка учитывается в своей категории.
+type Split struct {
+	ID          int
+	Amount      Money // общая сумма платежа в валюте Currency
+	Currency    string
+	Description string
+	Date        time.Time
+	Lines       []Transaction
+}
+
+func (s Split) Validate() error {
+	if s.Amount <= 0 {
+		return errors.New("сумма платежа должна быть положительным числом")
+	}
+	if err := ValidateCurrency(s.Currency); err != nil {
+		return err
+	}
+	if len(s.Lines) < 2 {
+		return errors.New("платёж должен быть разнесён хотя бы на две строки")
+	}
+
+	for _, line := range s.Lines {
+		if line.Amount <= 0 {
+			return errors.New("сумма строки должна быть положительным числом")
+		}
+		if strings.TrimSpace(line.Category) == "" {
+			return errors.New("категория строки не может быть пустой")
+		}
+		if err := ValidateCategory(line.Category); err != nil {
+			return err
+		}
+		if err := ValidateTags(line.Tags); err != nil {
+			return err
+		}
+		if line.Override && strings.TrimSpace(line.OverrideReason) == "" {
+			return errors.New("для подтверждения превышения нужна причина")
+		}
+	}
+	if s.LinesTotal() != s.Amount {
+		return errors.New("сумма строк должна совпадать с суммой платежа")
+	}
+	return nil
+}
+
+// LinesTotal возвращает сумму строк платежа.
+func (s Split) LinesTotal() Money {
+	var total Money
+	for _, line := range s.Lines {
+		total += line.Amount
+	}
+	return total
+}
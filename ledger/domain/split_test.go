@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitValidate(t *testing.T) {
+	t.Parallel()
+
+	line := func(category string, amount Money) Transaction {
+		return Transaction{Type: TransactionExpense, Category: category, Amount: amount}
+	}
+
+	testCases := []struct {
+		name    string
+		split   Split
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			split: Split{Amount: 150000, Lines: []Transaction{line("Продукты", 100000), line("Хозтовары", 50000)}},
+		},
+		{
+			name:    "sum mismatch",
+			split:   Split{Amount: 150000, Lines: []Transaction{line("Продукты", 100000), line("Хозтовары", 49999)}},
+			wantErr: true,
+		},
+		{
+			name:    "single line",
+			split:   Split{Amount: 150000, Lines: []Transaction{line("Продукты", 150000)}},
+			wantErr: true,
+		},
+		{
+			name:    "zero line",
+			split:   Split{Amount: 150000, Lines: []Transaction{line("Продукты", 150000), line("Хозтовары", 0)}},
+			wantErr: true,
+		},
+		{
+			name:    "empty category",
+			split:   Split{Amount: 150000, Lines: []Transaction{line("Продукты", 100000), line(" ", 50000)}},
+			wantErr: true,
+		},
+		{
+			name:    "empty category level",
+			split:   Split{Amount: 150000, Lines: []Transaction{line("Продукты", 100000), line("Дом//Хозтовары", 50000)}},
+			wantErr: true,
+		},
+		{
+			name:    "bad currency",
+			split:   Split{Amount: 150000, Currency: "EURO", Lines: []Transaction{line("Продукты", 100000), line("Хозтовары", 50000)}},
+			wantErr: true,
+		},
+		{
+			name: "override without reason",
+			split: Split{Amount: 150000, Lines: []Transaction{
+				line("Продукты", 100000),
+				{Type: TransactionExpense, Category: "Хозтовары", Amount: 50000, Override: true},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.split.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreateSplitRequestToEntity(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	req := CreateSplitRequest{
+		Amount:      150000,
+		Currency:    "eur",
+		Description: "Гипермаркет",
+		Date:        date,
+		Tags:        []string{"Дача"},
+		Lines: []SplitLineRequest{
+			{Category: "Продукты", Amount: 100000},
+			{Category: "Хозтовары", Amount: 50000, Description: "Лампочки"},
+		},
+	}
+
+	split := req.ToEntity()
+	if split.Currency != "EUR" {
+		t.Errorf("Expected currency EUR, got %s", split.Currency)
+	}
+	if len(split.Lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(split.Lines))
+	}
+
+	first, second := split.Lines[0], split.Lines[1]
+	if first.Description != "Гипермаркет" || second.Description != "Лампочки" {
+		t.Errorf("Expected descriptions from split and line, got %q and %q", first.Description, second.Description)
+	}
+	if first.Currency != "EUR" || !first.Date.Equal(date) || first.Type != TransactionExpense {
+		t.Errorf("Expected line to inherit currency, date and expense type, got %+v", first)
+	}
+	if len(second.Tags) != 1 || second.Tags[0] != "дача" {
+		t.Errorf("Expected normalized tags, got %v", second.Tags)
+	}
+	if err := split.Validate(); err != nil {
+		t.Errorf("Expected valid split, got %v", err)
+	}
+}
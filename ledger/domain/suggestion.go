@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultSuggestionPeriods    = 6
+	MaxSuggestionPeriods        = 24
+	DefaultSuggestionPercentile = 50 // медиана
+)
+
+// PeriodAmount — расходы категории за один период.
+type PeriodAmount struct {
+	PeriodStart time.Time
+	Amount      Money
+}
+
+// PeriodSpending — расходы категории за период, сгруппированные в хранилище.
+type PeriodSpending struct {
+	Category string
+	PeriodAmount
+}
+
+// BudgetSuggestion — предложенный лимит вместе с основанием: значениями периодов и исключёнными выбросами.
+type BudgetSuggestion struct {
+	Category   string
+	Period     string
+	Limit      Money
+	Percentile float64
+	Basis      []PeriodAmount
+	Outliers   []PeriodAmount
+}
+
+// NewBudgetSuggestion предлагает лимит как перцентиль расходов по периодам после исключения выбросов.
+func NewBudgetSuggestion(category, period string, amounts []PeriodAmount, percentile float64) BudgetSuggestion {
+	basis, outliers := SplitOutliers(amounts)
+
+	values := make([]float64, len(basis))
+	for i, amount := range basis {
+		values[i] = float64(amount.Amount)
+	}
+
+	return BudgetSuggestion{
+		Category:   category,
+		Period:     period,
+		Limit:      Money(math.Round(Percentile(values, percentile))),
+		Percentile: percentile,
+		Basis:      basis,
+		Outliers:   outliers,
+	}
+}
+
+// PreviousPeriodStarts возвращает начала n завершённых периодов перед периодом,
+// в который попадает at, от самого старого к самому новому.
+func PreviousPeriodStarts(period string, at time.Time, n int) []time.Time {
+	starts := make([]time.Time, n)
+
+	current, _ := PeriodRange(period, at)
+	for i := n - 1; i >= 0; i-- {
+		current, _ = PeriodRange(period, current.Add(-time.Second))
+		starts[i] = current
+	}
+
+	return starts
+}
+
+// Percentile считает перцентиль p (0..100) с линейной интерполяцией между соседними значениями.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// SplitOutliers отделяет значения за пределами полутора межквартильных размахов.
+// При меньше чем четырёх значениях квартили ненадёжны, и ничего не исключается.
+func SplitOutliers(amounts []PeriodAmount) ([]PeriodAmount, []PeriodAmount) {
+	if len(amounts) < 4 {
+		return amounts, nil
+	}
+
+	values := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		values[i] = float64(amount.Amount)
+	}
+
+	q1 := Percentile(values, 25)
+	q3 := Percentile(values, 75)
+	low := q1 - 1.5*(q3-q1)
+	high := q3 + 1.5*(q3-q1)
+
+	var kept, outliers []PeriodAmount
+	for _, amount := range amounts {
+		if value := float64(amount.Amount); value < low || value > high {
+			outliers = append(outliers, amount)
+		} else {
+			kept = append(kept, amount)
+		}
+	}
+
+	return kept, outliers
+}
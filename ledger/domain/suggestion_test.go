@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "empty", values: nil, p: 50, want: 0},
+		{name: "single value", values: []float64{300}, p: 90, want: 300},
+		{name: "median of odd count", values: []float64{300, 100, 200}, p: 50, want: 200},
+		{name: "median of even count", values: []float64{100, 400, 200, 300}, p: 50, want: 250},
+		{name: "interpolated percentile", values: []float64{100, 200, 300, 400, 500}, p: 90, want: 460},
+		{name: "maximum", values: []float64{100, 500, 200}, p: 100, want: 500},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Percentile(tc.values, tc.p)
+			if got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPreviousPeriodStarts(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		period string
+		want   []time.Time
+	}{
+		{
+			period: PeriodMonthly,
+			want: []time.Time{
+				time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			period: PeriodWeekly,
+			want: []time.Time{
+				time.Date(2024, 2, 19, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			period: PeriodDaily,
+			want: []time.Time{
+				time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.period, func(t *testing.T) {
+			t.Parallel()
+
+			got := PreviousPeriodStarts(tc.period, at, 3)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewBudgetSuggestion(t *testing.T) {
+	t.Parallel()
+
+	month := func(m time.Month) time.Time { return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	amounts := []PeriodAmount{
+		{PeriodStart: month(1), Amount: 1000},
+		{PeriodStart: month(2), Amount: 1200},
+		{PeriodStart: month(3), Amount: 9000},
+		{PeriodStart: month(4), Amount: 1100},
+		{PeriodStart: month(5), Amount: 900},
+		{PeriodStart: month(6), Amount: 1300},
+	}
+
+	got := NewBudgetSuggestion("food", PeriodMonthly, amounts, 50)
+
+	if got.Limit != 1100 {
+		t.Errorf("Expected limit 1100, got %v", got.Limit)
+	}
+	if len(got.Basis) != 5 {
+		t.Errorf("Expected 5 basis periods, got %d", len(got.Basis))
+	}
+	wantOutliers := []PeriodAmount{{PeriodStart: month(3), Amount: 9000}}
+	if !reflect.DeepEqual(got.Outliers, wantOutliers) {
+		t.Errorf("Expected outliers %v, got %v", wantOutliers, got.Outliers)
+	}
+
+	few := NewBudgetSuggestion("food", PeriodMonthly, amounts[1:4], 50)
+	if len(few.Outliers) != 0 {
+		t.Errorf("Expected no outliers for three periods, got %v", few.Outliers)
+	}
+	if few.Limit != 1200 {
+		t.Errorf("Expected limit 1200, got %v", few.Limit)
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTagLength — наибольшая длина метки в символах.
+const MaxTagLength = 50
+
+// NormalizeTags приводит метки к нижнему регистру, убирает пустые и повторы и сортирует их.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// ValidateTags проверяет метки: не длиннее MaxTagLength и без запятых,
+// которыми метки разделяются в параметрах запросов.
+func ValidateTags(tags []string) error {
+	for _, tag := range tags {
+		if utf8.RuneCountInString(strings.TrimSpace(tag)) > MaxTagLength {
+			return errors.New("tag cannot be longer than 50 characters")
+		}
+		if strings.Contains(tag, ",") {
+			return errors.New("tag cannot contain commas")
+		}
+	}
+	return nil
+}
+
+// TagSpending — расходы с меткой за период. Транзакция с несколькими метками
+// учитывается в каждой из них.
+type TagSpending struct {
+	Tag          string
+	Amount       Money
+	Transactions int
+}
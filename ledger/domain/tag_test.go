@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "nil", tags: nil, want: []string{}},
+		{name: "trim and lower", tags: []string{" Отпуск-2024 ", "работа"}, want: []string{"отпуск-2024", "работа"}},
+		{name: "duplicates", tags: []string{"работа", "Работа", ""}, want: []string{"работа"}},
+		{name: "sorted", tags: []string{"b", "a"}, want: []string{"a", "b"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NormalizeTags(tc.tags)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		tags    []string
+		wantErr bool
+	}{
+		{name: "valid", tags: []string{"отпуск-2024", "работа-возмещение"}},
+		{name: "empty", tags: nil},
+		{name: "comma", tags: []string{"a,b"}, wantErr: true},
+		{name: "max length", tags: []string{strings.Repeat("я", MaxTagLength)}},
+		{name: "too long", tags: []string{strings.Repeat("я", MaxTagLength+1)}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateTags(tc.tags)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
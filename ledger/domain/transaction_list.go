@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Порядок списка транзакций. Минус означает убывание; при равенстве
+// транзакции упорядочиваются по ID в том же направлении.
+const (
+	SortDateDesc   = "-date"
+	SortDateAsc    = "date"
+	SortAmountDesc = "-amount"
+	SortAmountAsc  = "amount"
+)
+
+// Размер страницы списка транзакций.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TransactionFilter отбирает транзакции для списка. Пустой фильтр не ограничивает выборку,
+// кроме Limit: 0 — без ограничения числа строк.
+type TransactionFilter struct {
+	Category    string     // категория вместе с подкатегориями
+	From        *time.Time // включительно
+	To          *time.Time // включительно
+	MinAmount   *Money     // сумма в валюте транзакции, включительно
+	MaxAmount   *Money
+	Description string   // подстрока описания без учёта регистра
+	Tags        []string // транзакции хотя бы с одной из меток
+	Sort        string
+	Limit       int
+	After       *TransactionCursor // продолжить после этой транзакции
+}
+
+func (f TransactionFilter) Validate() error {
+	switch f.Sort {
+	case SortDateDesc, SortDateAsc, SortAmountDesc, SortAmountAsc:
+	default:
+		return errors.New("sort must be 'date', '-date', 'amount' or '-amount'")
+	}
+	if f.Limit < 0 || f.Limit > MaxPageSize {
+		return errors.New("limit must be between 1 and 500")
+	}
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return errors.New("from date cannot be after to date")
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return errors.New("min_amount cannot be greater than max_amount")
+	}
+	if f.After != nil && f.After.Sort != f.Sort {
+		return errors.New("cursor was issued for another sort order")
+	}
+	return nil
+}
+
+// TransactionCursor — позиция в списке: ключ сортировки и ID последней выданной транзакции.
+type TransactionCursor struct {
+	Sort   string    `json:"s"`
+	Date   time.Time `json:"d,omitzero"`
+	Amount Money     `json:"a,omitzero"`
+	ID     int       `json:"i"`
+}
+
+// CursorAfter возвращает курсор, с которого продолжается список после транзакции tx.
+func CursorAfter(sort string, tx Transaction) TransactionCursor {
+	cursor := TransactionCursor{Sort: sort, ID: tx.ID}
+	if strings.TrimPrefix(sort, "-") == SortAmountAsc {
+		cursor.Amount = tx.Amount
+	} else {
+		cursor.Date = tx.Date
+	}
+	return cursor
+}
+
+// Encode упаковывает курсор в непрозрачную строку для параметра cursor.
+func (c TransactionCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку, полученную из Encode.
+func DecodeCursor(value string) (TransactionCursor, error) {
+	var cursor TransactionCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
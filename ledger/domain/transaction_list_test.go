@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransactionCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tx := Transaction{ID: 42, Amount: 150050, Date: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)}
+
+	testCases := []struct {
+		name string
+		sort string
+	}{
+		{name: "by date", sort: SortDateDesc},
+		{name: "by amount", sort: SortAmountAsc},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			want := CursorAfter(tc.sort, tx)
+			got, err := DecodeCursor(want.Encode())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got.Sort != want.Sort || got.ID != want.ID || got.Amount != want.Amount || !got.Date.Equal(want.Date) {
+				t.Errorf("Expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := DecodeCursor(value); err != ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", value, err)
+		}
+	}
+}
+
+func TestListTransactionsRequestToFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := ListTransactionsRequest{Tags: []string{"Отпуск"}}.ToFilter()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if filter.Sort != SortDateDesc {
+		t.Errorf("Expected sort %s, got %s", SortDateDesc, filter.Sort)
+	}
+	if filter.Limit != DefaultPageSize {
+		t.Errorf("Expected limit %d, got %d", DefaultPageSize, filter.Limit)
+	}
+	if len(filter.Tags) != 1 || filter.Tags[0] != "отпуск" {
+		t.Errorf("Expected tags [отпуск], got %v", filter.Tags)
+	}
+}
+
+func TestTransactionFilterValidate(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	minAmount, maxAmount := Money(5000), Money(1000)
+
+	testCases := []struct {
+		name    string
+		filter  TransactionFilter
+		wantErr bool
+	}{
+		{name: "valid", filter: TransactionFilter{Sort: SortAmountDesc, Limit: 10}},
+		{name: "unknown sort", filter: TransactionFilter{Sort: "category", Limit: 10}, wantErr: true},
+		{name: "limit too large", filter: TransactionFilter{Sort: SortDateDesc, Limit: MaxPageSize + 1}, wantErr: true},
+		{name: "from after to", filter: TransactionFilter{Sort: SortDateDesc, From: &from, To: &to}, wantErr: true},
+		{name: "min above max", filter: TransactionFilter{Sort: SortDateDesc, MinAmount: &minAmount, MaxAmount: &maxAmount}, wantErr: true},
+		{
+			name:    "cursor for other sort",
+			filter:  TransactionFilter{Sort: SortDateAsc, After: &TransactionCursor{Sort: SortDateDesc, ID: 1}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.filter.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
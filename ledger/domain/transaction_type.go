@@ -0,0 +1,46 @@
+package domain
+
+import "errors"
+
+// Типы транзакций.
+const (
+	TransactionExpense = "expense" // расход, уменьшает остаток бюджета
+	TransactionRefund  = "refund"  // возврат, хранится с отрицательной суммой и уменьшает расходы категории
+	TransactionIncome  = "income"  // доход, в расходы и бюджеты не входит
+)
+
+// ValidateTransactionType проверяет тип транзакции; пустой означает расход.
+func ValidateTransactionType(kind string) error {
+	switch kind {
+	case "", TransactionExpense, TransactionRefund, TransactionIncome:
+		return nil
+	}
+	return errors.New("transaction type must be 'expense', 'refund' or 'income'")
+}
+
+// SignedAmount возвращает сумму со знаком для хранения: в запросах сумма всегда
+// положительная, а возвраты хранятся отрицательными.
+func SignedAmount(kind string, amount Money) Money {
+	if kind == TransactionRefund {
+		return -amount
+	}
+	return amount
+}
+
+// CashFlow — движение денег за период по типам транзакций в валюте Currency.
+type CashFlow struct {
+	Income   Money
+	Expenses Money
+	Refunds  Money
+	Currency string
+}
+
+// NetSpending — расходы за вычетом возвратов.
+func (c CashFlow) NetSpending() Money {
+	return c.Expenses - c.Refunds
+}
+
+// Net — чистый денежный поток: доходы минус расходы с учётом возвратов.
+func (c CashFlow) Net() Money {
+	return c.Income - c.NetSpending()
+}
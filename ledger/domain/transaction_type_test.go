@@ -0,0 +1,60 @@
+package domain
+
+import "testing"
+
+func TestUpdateTransactionRequestApply(t *testing.T) {
+	t.Parallel()
+
+	refund := TransactionRefund
+	amount := Money(30000)
+
+	current := Transaction{Type: TransactionExpense, Amount: 100000, Category: "Одежда"}
+
+	testCases := []struct {
+		name       string
+		req        UpdateTransactionRequest
+		wantType   string
+		wantAmount Money
+	}{
+		{name: "no changes", req: UpdateTransactionRequest{}, wantType: TransactionExpense, wantAmount: 100000},
+		{name: "new amount", req: UpdateTransactionRequest{Amount: &amount}, wantType: TransactionExpense, wantAmount: 30000},
+		{name: "becomes refund", req: UpdateTransactionRequest{Type: &refund}, wantType: TransactionRefund, wantAmount: -100000},
+		{name: "refund with new amount", req: UpdateTransactionRequest{Type: &refund, Amount: &amount}, wantType: TransactionRefund, wantAmount: -30000},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.req.Apply(current)
+			if got.Type != tc.wantType || got.Amount != tc.wantAmount {
+				t.Errorf("Expected %s %v, got %s %v", tc.wantType, tc.wantAmount, got.Type, got.Amount)
+			}
+		})
+	}
+}
+
+func TestCashFlowNet(t *testing.T) {
+	t.Parallel()
+
+	cashFlow := CashFlow{Income: 10000000, Expenses: 4500050, Refunds: 200025}
+
+	if got := cashFlow.NetSpending(); got != 4300025 {
+		t.Errorf("Expected net spending 43000.25, got %v", got)
+	}
+	if got := cashFlow.Net(); got != 5699975 {
+		t.Errorf("Expected net 56999.75, got %v", got)
+	}
+}
+
+func TestIncomeDoesNotCountTowardBudgets(t *testing.T) {
+	t.Parallel()
+
+	transaction := Transaction{Type: TransactionIncome, Amount: 50000, Category: "Зарплата"}
+	from, to := PeriodRange(PeriodMonthly, transaction.Date)
+
+	if transaction.CountsToward("Зарплата", from, to) {
+		t.Errorf("Expected income to be excluded from spending")
+	}
+}
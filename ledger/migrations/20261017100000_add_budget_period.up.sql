@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE budgets
+    ADD COLUMN period TEXT NOT NULL DEFAULT 'monthly'
+        CHECK (period IN ('daily', 'weekly', 'monthly'));
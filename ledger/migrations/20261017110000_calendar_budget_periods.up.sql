@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE budgets DROP CONSTRAINT budgets_category_key;
+ALTER TABLE budgets DROP CONSTRAINT budgets_period_check;
+
+ALTER TABLE budgets
+    ADD COLUMN period_start DATE,
+    ADD COLUMN period_end DATE,
+    ADD CONSTRAINT budgets_category_period_key UNIQUE (category, period),
+    ADD CONSTRAINT budgets_period_range_check CHECK (
+        (period_start IS NULL AND period_end IS NULL AND period IN ('daily', 'weekly', 'monthly'))
+        OR (period_start IS NOT NULL AND period_end >= period_start)
+    );
+
+CREATE INDEX idx_budgets_category_range ON budgets(category, period_start, period_end);
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE budgets
+    ADD COLUMN rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT now(),
+    ADD CONSTRAINT budgets_rollover_recurring_check CHECK (NOT rollover OR period_start IS NULL);
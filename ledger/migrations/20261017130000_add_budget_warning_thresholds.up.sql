@@ -0,0 +1,3 @@
+-- +goose Up
+ALTER TABLE budgets
+    ADD COLUMN warning_thresholds JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE categories (
+                            id SERIAL PRIMARY KEY,
+                            name TEXT UNIQUE NOT NULL,
+                            parent_id INT REFERENCES categories(id)
+);
+
+CREATE INDEX idx_categories_parent ON categories(parent_id);
+
+-- Категории из существующих транзакций и бюджетов вместе со всеми родителями ("А/Б/В" -> "А", "А/Б", "А/Б/В")
+INSERT INTO categories (name)
+SELECT DISTINCT array_to_string((string_to_array(c.name, '/'))[1:n], '/')
+FROM (SELECT category AS name FROM expenses UNION SELECT category FROM budgets) c,
+     generate_series(1, array_length(string_to_array(c.name, '/'), 1)) AS n
+ON CONFLICT (name) DO NOTHING;
+
+UPDATE categories child
+SET parent_id = parent.id
+FROM categories parent
+WHERE position('/' IN child.name) > 0
+  AND parent.name = regexp_replace(child.name, '/[^/]*$', '');
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE budget_pools (
+                              id SERIAL PRIMARY KEY,
+                              name TEXT UNIQUE NOT NULL,
+                              limit_amount NUMERIC(14,2) NOT NULL CHECK (limit_amount > 0),
+                              period TEXT NOT NULL DEFAULT 'monthly',
+                              period_start DATE,
+                              period_end DATE,
+                              created_at TIMESTAMP NOT NULL DEFAULT now(),
+                              CHECK (
+                                  (period_start IS NULL AND period_end IS NULL AND period IN ('daily', 'weekly', 'monthly'))
+                                  OR (period_start IS NOT NULL AND period_end >= period_start)
+                              )
+);
+
+CREATE TABLE budget_pool_categories (
+                                        pool_id INT NOT NULL REFERENCES budget_pools(id) ON DELETE CASCADE,
+                                        category TEXT NOT NULL,
+                                        PRIMARY KEY (pool_id, category)
+);
+
+CREATE INDEX idx_budget_pool_categories_category ON budget_pool_categories(category);
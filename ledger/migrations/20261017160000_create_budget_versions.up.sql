@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE budget_versions (
+                                 id SERIAL PRIMARY KEY,
+                                 budget_id INT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+                                 limit_amount NUMERIC(14,2) NOT NULL CHECK (limit_amount > 0),
+                                 effective_from DATE, -- NULL: исходный лимит, действующий с самого начала
+                                 created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_budget_versions_budget_effective ON budget_versions(budget_id, effective_from);
+
+INSERT INTO budget_versions (budget_id, limit_amount, effective_from, created_at)
+SELECT id, limit_amount, NULL, created_at
+FROM budgets;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE budgets
+    ADD COLUMN overspend_policy TEXT NOT NULL DEFAULT 'block'
+        CHECK (overspend_policy IN ('block', 'allow', 'override'));
+
+CREATE TABLE overspend_records (
+                                   id SERIAL PRIMARY KEY,
+                                   expense_id INT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+                                   budget_category TEXT NOT NULL,
+                                   policy TEXT NOT NULL,
+                                   reason TEXT NOT NULL,
+                                   limit_amount NUMERIC(14,2) NOT NULL,
+                                   spent_amount NUMERIC(14,2) NOT NULL,
+                                   created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_overspend_records_expense ON overspend_records(expense_id);
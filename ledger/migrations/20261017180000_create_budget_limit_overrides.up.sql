@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE budget_limit_overrides (
+                                        id SERIAL PRIMARY KEY,
+                                        budget_id INT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+                                        kind TEXT NOT NULL CHECK (kind IN ('replace', 'increment')),
+                                        amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+                                        starts_on DATE NOT NULL,
+                                        ends_on DATE NOT NULL,
+                                        reason TEXT NOT NULL DEFAULT '',
+                                        created_at TIMESTAMP NOT NULL DEFAULT now(),
+                                        CHECK (ends_on >= starts_on)
+);
+
+CREATE INDEX idx_budget_limit_overrides_budget_dates ON budget_limit_overrides(budget_id, starts_on, ends_on);
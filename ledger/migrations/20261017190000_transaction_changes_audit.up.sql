@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE expenses
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN reverses_id INT REFERENCES expenses(id);
+
+-- Сторнировать запись можно только один раз
+CREATE UNIQUE INDEX idx_expenses_reverses ON expenses(reverses_id) WHERE reverses_id IS NOT NULL;
+
+CREATE TABLE expense_audit (
+                               id SERIAL PRIMARY KEY,
+                               expense_id INT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+                               action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'reverse')),
+                               old_values JSONB,
+                               new_values JSONB,
+                               created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_expense_audit_expense ON expense_audit(expense_id, id);
+
+INSERT INTO expense_audit (expense_id, action, new_values, created_at)
+SELECT id, 'create',
+       jsonb_build_object('amount', amount, 'category', category,
+                          'description', COALESCE(description, ''),
+                          'date', to_char(date, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+       date
+FROM expenses;
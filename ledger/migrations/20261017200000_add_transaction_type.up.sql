@@ -0,0 +1,6 @@
+-- +goose Up
+-- Возвраты хранятся с отрицательной суммой и уменьшают расходы категории,
+-- доходы в расходы не входят и учитываются только в отчёте о движении денег.
+ALTER TABLE expenses
+    ADD COLUMN type TEXT NOT NULL DEFAULT 'expense'
+        CHECK (type IN ('expense', 'refund', 'income'));
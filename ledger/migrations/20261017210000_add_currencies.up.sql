@@ -0,0 +1,64 @@
+-- +goose Up
+-- Суммы транзакций хранятся в исходной валюте, лимиты бюджетов — в валюте бюджета.
+-- Общие бюджеты ведутся в базовой валюте RUB.
+ALTER TABLE expenses
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE budgets
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+-- Одна единица currency стоит rate единиц base_currency начиная с rate_date.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+                                              currency CHAR(3) NOT NULL,
+                                              base_currency CHAR(3) NOT NULL,
+                                              rate_date DATE NOT NULL,
+                                              rate NUMERIC(20, 8) NOT NULL CHECK (rate > 0),
+                                              created_at TIMESTAMP DEFAULT NOW(),
+                                              PRIMARY KEY (currency, base_currency, rate_date),
+                                              CHECK (currency <> base_currency)
+);
+
+-- exchange_rate возвращает последний на дату on_date курс пары, прямой или обратный.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION exchange_rate(from_currency CHAR(3), to_currency CHAR(3), on_date DATE)
+    RETURNS NUMERIC AS $$
+DECLARE
+    result NUMERIC;
+BEGIN
+    IF from_currency = to_currency THEN
+        RETURN 1;
+    END IF;
+
+    SELECT r.rate INTO result
+    FROM (
+             SELECT rate, rate_date
+             FROM exchange_rates
+             WHERE currency = from_currency AND base_currency = to_currency AND rate_date <= on_date
+             UNION ALL
+             SELECT 1 / rate, rate_date
+             FROM exchange_rates
+             WHERE currency = to_currency AND base_currency = from_currency AND rate_date <= on_date
+         ) r
+    ORDER BY r.rate_date DESC
+    LIMIT 1;
+
+    IF result IS NULL THEN
+        RAISE EXCEPTION 'no exchange rate from % to % on %', from_currency, to_currency, on_date
+            USING ERRCODE = 'no_data_found';
+    END IF;
+
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql STABLE;
+-- +goose StatementEnd
+
+-- convert_amount переводит сумму в валюту to_currency по курсу на дату on_date с округлением до копейки.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION convert_amount(amount NUMERIC, from_currency CHAR(3), to_currency CHAR(3), on_date DATE)
+    RETURNS NUMERIC AS $$
+SELECT CASE
+           WHEN from_currency = to_currency THEN amount
+           ELSE ROUND(amount * exchange_rate(from_currency, to_currency, on_date), 2)
+           END;
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tags (
+                                    id SERIAL PRIMARY KEY,
+                                    name TEXT NOT NULL UNIQUE,
+                                    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS expense_tags (
+                                            expense_id INTEGER NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+                                            tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                                            PRIMARY KEY (expense_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_tags_tag ON expense_tags(tag_id);
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Разнесённый платёж: строки хранятся в expenses со ссылкой split_id и учитываются
+-- в бюджетах и отчётах каждая в своей категории.
+CREATE TABLE IF NOT EXISTS transaction_splits (
+                                                  id SERIAL PRIMARY KEY,
+                                                  amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+                                                  currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                                                  description TEXT,
+                                                  date TIMESTAMP NOT NULL,
+                                                  created_at TIMESTAMP DEFAULT NOW()
+);
+
+ALTER TABLE expenses
+    ADD COLUMN split_id INTEGER REFERENCES transaction_splits(id);
+
+CREATE INDEX IF NOT EXISTS idx_expenses_split ON expenses(split_id) WHERE split_id IS NOT NULL;
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+                                                      id SERIAL PRIMARY KEY,
+                                                      type TEXT NOT NULL DEFAULT 'expense'
+                                                          CHECK (type IN ('expense', 'refund', 'income')),
+                                                      amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+                                                      currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                                                      category TEXT NOT NULL,
+                                                      description TEXT,
+                                                      tags JSONB NOT NULL DEFAULT '[]',
+                                                      frequency TEXT NOT NULL
+                                                          CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+                                                      interval_count INTEGER NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+                                                      day_of_month INTEGER NOT NULL DEFAULT 0 CHECK (day_of_month BETWEEN 0 AND 31),
+                                                      starts_on DATE NOT NULL,
+                                                      ends_on DATE,
+                                                      active BOOLEAN NOT NULL DEFAULT TRUE,
+                                                      created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Уникальность (recurring_id, due_on) не даёт провести одну дату дважды,
+-- даже если планировщики нескольких экземпляров сработали одновременно.
+CREATE TABLE IF NOT EXISTS recurring_occurrences (
+                                                     id SERIAL PRIMARY KEY,
+                                                     recurring_id INTEGER NOT NULL REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+                                                     due_on DATE NOT NULL,
+                                                     status TEXT NOT NULL DEFAULT 'pending'
+                                                         CHECK (status IN ('pending', 'posted', 'rejected')),
+                                                     expense_id INTEGER REFERENCES expenses(id),
+                                                     error TEXT,
+                                                     created_at TIMESTAMP DEFAULT NOW(),
+                                                     UNIQUE (recurring_id, due_on)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_occurrences_status ON recurring_occurrences(status);
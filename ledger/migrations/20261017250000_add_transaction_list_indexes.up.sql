@@ -0,0 +1,4 @@
+-- +goose Up
+-- Индексы под постраничный вывод транзакций: ORDER BY ключ, id с условием по курсору.
+CREATE INDEX IF NOT EXISTS idx_expenses_date_id ON expenses(date, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_amount_id ON expenses(amount, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Поисковый вектор описания: русская и английская морфология, чтобы находились
+-- и "такси в аэропорт", и "airport taxi".
+ALTER TABLE expenses
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('russian', COALESCE(description, '')) ||
+        to_tsvector('english', COALESCE(description, ''))
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_expenses_search ON expenses USING GIN (search_vector);
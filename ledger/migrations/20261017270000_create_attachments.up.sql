@@ -0,0 +1,15 @@
+-- +goose Up
+-- Вложения транзакций; содержимое лежит в хранилище файлов по sha256,
+-- одинаковые файлы разных вложений хранятся один раз.
+CREATE TABLE IF NOT EXISTS attachments (
+                                           id SERIAL PRIMARY KEY,
+                                           expense_id INTEGER NOT NULL REFERENCES expenses(id),
+                                           filename TEXT NOT NULL,
+                                           content_type TEXT NOT NULL,
+                                           size BIGINT NOT NULL CHECK (size >= 0),
+                                           sha256 CHAR(64) NOT NULL,
+                                           created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_expense ON attachments(expense_id, id);
+CREATE INDEX IF NOT EXISTS idx_attachments_sha256 ON attachments(sha256);
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Ключи идемпотентности запросов на создание транзакций. Пока запрос выполняется,
+-- status_code пуст; после выполнения хранится ответ для повторов до expires_at.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                                key TEXT PRIMARY KEY,
+                                                method TEXT NOT NULL,
+                                                path TEXT NOT NULL,
+                                                fingerprint CHAR(64) NOT NULL,
+                                                status_code INTEGER,
+                                                headers JSONB,
+                                                body BYTEA,
+                                                created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                                expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
@@ -0,0 +1,145 @@
+package fs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"ledger/domain"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// blobStore хранит содержимое в файлах root/ab/cd/<sha256>. Файл сначала пишется
+// во временный и затем связывается с постоянным именем, поэтому читатели не видят его недописанным.
+type blobStore struct {
+	root string
+}
+
+func NewBlobStore(root string) (domain.BlobStore, error) {
+	if err := os.MkdirAll(filepath.Join(root, "tmp"), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &blobStore{root: root}, nil
+}
+
+func (s *blobStore) Stage(ctx context.Context, content io.Reader) (domain.StagedBlob, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "blob-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary blob: %w", err)
+	}
+
+	staged := &stagedBlob{store: s, tmp: tmp.Name()}
+	if err := staged.write(ctx, tmp, content); err != nil {
+		staged.Discard()
+		return nil, err
+	}
+
+	return staged, nil
+}
+
+func (s *blobStore) Open(ctx context.Context, sum string) (io.ReadCloser, error) {
+	if !hashPattern.MatchString(sum) {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	file, err := os.Open(s.path(sum))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	return file, nil
+}
+
+func (s *blobStore) Delete(ctx context.Context, sum string) error {
+	if !hashPattern.MatchString(sum) {
+		return nil
+	}
+
+	err := os.Remove(s.path(sum))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *blobStore) path(sum string) string {
+	return filepath.Join(s.root, sum[:2], sum[2:4], sum)
+}
+
+// stagedBlob — временный файл в root/tmp с уже посчитанным хешем.
+type stagedBlob struct {
+	store *blobStore
+	tmp   string
+	sum   string
+	size  int64
+}
+
+func (b *stagedBlob) write(ctx context.Context, tmp *os.File, content io.Reader) error {
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), content)
+	if err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.sum = hex.EncodeToString(hash.Sum(nil))
+	b.size = size
+	return nil
+}
+
+func (b *stagedBlob) SHA256() string {
+	return b.sum
+}
+
+func (b *stagedBlob) Size() int64 {
+	return b.size
+}
+
+// Commit создаёт жёсткую ссылку на временный файл, а не переносит его, поэтому
+// после отката транзакции БД Commit можно повторить, даже если файл успели удалить.
+func (b *stagedBlob) Commit(ctx context.Context) error {
+	path := b.store.path(b.sum)
+
+	// Такое содержимое уже есть — копия не нужна
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	if err := os.Link(b.tmp, path); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return nil
+}
+
+func (b *stagedBlob) Discard() error {
+	err := os.Remove(b.tmp)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete temporary blob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ledger/domain"
+)
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) domain.AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func scanAttachment(row rowScanner) (domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := row.Scan(
+		&attachment.ID, &attachment.TransactionID, &attachment.Filename, &attachment.ContentType,
+		&attachment.Size, &attachment.SHA256, &attachment.CreatedAt,
+	)
+	return attachment, err
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO attachments (expense_id, filename, content_type, size, sha256)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		attachment.TransactionID,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.SHA256,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) List(ctx context.Context, transactionID int) ([]domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, filename, content_type, size, sha256, created_at
+		FROM attachments
+		WHERE expense_id = $1
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []domain.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepository) Get(ctx context.Context, transactionID, id int) (*domain.Attachment, error) {
+	query := `
+		SELECT id, expense_id, filename, content_type, size, sha256, created_at
+		FROM attachments
+		WHERE expense_id = $1 AND id = $2
+	`
+
+	attachment, err := scanAttachment(conn(ctx, r.db).QueryRowContext(ctx, query, transactionID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM attachments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) References(ctx context.Context, sha256 string) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM attachments WHERE sha256 = $1`, sha256).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count attachment references: %w", err)
+	}
+
+	return count, nil
+}
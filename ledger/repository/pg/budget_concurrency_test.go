@@ -0,0 +1,158 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"ledger/domain"
+	"ledger/service"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// openTestDB подключается к базе из DATABASE_URL с применёнными миграциями.
+// Без DATABASE_URL тест пропускается.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("Expected no error opening database, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		t.Fatalf("Expected no error pinging database, got %v", err)
+	}
+
+	return db
+}
+
+// cleanupCategory удаляет транзакции, бюджет и категории теста вместе с подкатегориями.
+func cleanupCategory(t *testing.T, db *sql.DB, category string) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		queries := []string{
+			`DELETE FROM expenses WHERE category = $1 OR category LIKE $1 || '/%'`,
+			`DELETE FROM budgets WHERE category = $1`,
+			`DELETE FROM categories WHERE name LIKE $1 || '/%'`,
+			`DELETE FROM categories WHERE name = $1`,
+		}
+		for _, query := range queries {
+			if _, err := db.Exec(query, category); err != nil {
+				t.Errorf("Failed to clean up %s: %v", category, err)
+			}
+		}
+	})
+}
+
+func newTestService(db *sql.DB) service.LedgerService {
+	return service.NewLedgerService(
+		NewTransactionRepository(db),
+		NewBudgetRepository(db),
+		NewCategoryRepository(db),
+		NewBudgetPoolRepository(db),
+		NewOverspendRepository(db),
+		NewExchangeRateRepository(db),
+		NewRecurringRepository(db),
+		NewAttachmentRepository(db),
+		nil,
+		nil,
+		NewTransactor(db),
+	)
+}
+
+// Проверка бюджета и вставка идут через настоящие advisory-блокировки и повторы транзакций.
+func TestCreateTransactionConcurrentBudgetLimitPostgres(t *testing.T) {
+	db := openTestDB(t)
+	db.SetMaxOpenConns(10)
+
+	const limit = domain.Money(100000)
+	const amount = domain.Money(10000)
+
+	testCases := []struct {
+		name          string
+		subcategories []string
+	}{
+		{name: "same category"},
+		{name: "sibling subcategories", subcategories: []string{"Такси", "Метро"}},
+	}
+
+	for i, tc := range testCases {
+		category := fmt.Sprintf("Тест-%d-%d", time.Now().UnixNano(), i)
+		cleanupCategory(t, db, category)
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			ledger := newTestService(db)
+
+			if _, err := ledger.CreateBudget(ctx, domain.CreateBudgetRequest{Category: category, Limit: limit}); err != nil {
+				t.Fatalf("Expected no error creating budget, got %v", err)
+			}
+
+			categories := []string{category}
+			if len(tc.subcategories) > 0 {
+				categories = nil
+				for _, subcategory := range tc.subcategories {
+					categories = append(categories, category+"/"+subcategory)
+				}
+			}
+
+			const requests = 40
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			accepted := 0
+
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+
+					_, err := ledger.CreateTransaction(ctx, domain.CreateTransactionRequest{
+						Amount:   amount,
+						Category: categories[i%len(categories)],
+					})
+					if err != nil && !errors.Is(err, domain.ErrBudgetExceeded) {
+						t.Errorf("Expected nil or ErrBudgetExceeded, got %v", err)
+						return
+					}
+					if err == nil {
+						mu.Lock()
+						accepted++
+						mu.Unlock()
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			var total domain.Money
+			err := db.QueryRow(
+				`SELECT COALESCE(SUM(amount), 0) FROM expenses WHERE (category = $1 OR category LIKE $1 || '/%') AND deleted_at IS NULL`,
+				category,
+			).Scan(&total)
+			if err != nil {
+				t.Fatalf("Expected no error reading spending, got %v", err)
+			}
+
+			if total > limit {
+				t.Errorf("Expected spending within limit %s, got %s", limit, total)
+			}
+			if want := int(limit / amount); accepted != want {
+				t.Errorf("Expected %d accepted transactions, got %d", want, accepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ledger/domain"
+	"time"
+)
+
+const budgetPoolSelect = `
+	SELECT p.id, p.name, p.limit_amount, p.period,
+	       COALESCE(json_agg(pc.category ORDER BY pc.category) FILTER (WHERE pc.category IS NOT NULL), '[]')
+	FROM budget_pools p
+	LEFT JOIN budget_pool_categories pc ON pc.pool_id = p.id
+`
+
+type budgetPoolRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetPoolRepository(db *sql.DB) domain.BudgetPoolRepository {
+	return &budgetPoolRepository{db: db}
+}
+
+func (r *budgetPoolRepository) Save(ctx context.Context, pool *domain.BudgetPool) error {
+	period := pool.Period
+	if period == "" {
+		period = domain.PeriodMonthly
+	}
+
+	periodStart, periodEnd, err := calendarBounds(period)
+	if err != nil {
+		return fmt.Errorf("failed to parse budget pool period: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO budget_pools (name, limit_amount, period, period_start, period_end)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name)
+		DO UPDATE SET limit_amount = EXCLUDED.limit_amount,
+		              period = EXCLUDED.period,
+		              period_start = EXCLUDED.period_start,
+		              period_end = EXCLUDED.period_end
+		RETURNING id
+	`
+
+	err = tx.QueryRowContext(ctx, query, pool.Name, pool.Limit, period, periodStart, periodEnd).Scan(&pool.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save budget pool: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM budget_pool_categories WHERE pool_id = $1`, pool.ID); err != nil {
+		return fmt.Errorf("failed to reset budget pool categories: %w", err)
+	}
+
+	for _, category := range pool.Categories {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO budget_pool_categories (pool_id, category)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, pool.ID, category)
+		if err != nil {
+			return fmt.Errorf("failed to add category %s to budget pool: %w", category, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit budget pool: %w", err)
+	}
+
+	pool.Period = period
+	return nil
+}
+
+func (r *budgetPoolRepository) List(ctx context.Context) ([]domain.BudgetPool, error) {
+	query := budgetPoolSelect + `
+		GROUP BY p.id
+		ORDER BY p.name
+	`
+
+	return r.query(ctx, query)
+}
+
+func (r *budgetPoolRepository) ListByCategories(ctx context.Context, categories []string, date time.Time) ([]domain.BudgetPool, error) {
+	query := budgetPoolSelect + `
+		WHERE p.id IN (SELECT pool_id FROM budget_pool_categories WHERE category = ANY($1))
+		  AND (p.period_start IS NULL OR $2::date BETWEEN p.period_start AND p.period_end)
+		GROUP BY p.id
+		ORDER BY p.name
+	`
+
+	return r.query(ctx, query, categories, date.Format("2006-01-02"))
+}
+
+func (r *budgetPoolRepository) query(ctx context.Context, query string, args ...any) ([]domain.BudgetPool, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget pools: %w", err)
+	}
+	defer rows.Close()
+
+	var pools []domain.BudgetPool
+	for rows.Next() {
+		var pool domain.BudgetPool
+		var categories []byte
+
+		if err := rows.Scan(&pool.ID, &pool.Name, &pool.Limit, &pool.Period, &categories); err != nil {
+			return nil, fmt.Errorf("failed to scan budget pool: %w", err)
+		}
+
+		if err := json.Unmarshal(categories, &pool.Categories); err != nil {
+			return nil, fmt.Errorf("failed to decode budget pool categories: %w", err)
+		}
+
+		pools = append(pools, pool)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating budget pools: %w", err)
+	}
+
+	return pools, nil
+}
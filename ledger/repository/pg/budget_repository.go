@@ -3,43 +3,164 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"ledger/domain"
+	"time"
 )
 
+// limitAtQuery выбирает лимит версии бюджета b, действующей на дату из параметра %s.
+const limitAtQuery = `COALESCE((
+		SELECT v.limit_amount
+		FROM budget_versions v
+		WHERE v.budget_id = b.id AND (v.effective_from IS NULL OR v.effective_from <= %s::date)
+		ORDER BY v.effective_from DESC NULLS LAST, v.id DESC
+		LIMIT 1
+	), b.limit_amount)`
+
+// budgetColumns перечисляет колонки для scanBudget; лимит берётся из версии,
+// действующей на дату из параметра dateParam.
+func budgetColumns(dateParam string) string {
+	return `b.id, b.category, ` + fmt.Sprintf(limitAtQuery, dateParam) +
+		`, b.currency, b.period, b.rollover, b.warning_thresholds, b.overspend_policy, b.created_at`
+}
+
 type budgetRepository struct {
 	db *sql.DB
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// calendarBounds возвращает даты начала и конца календарного периода для колонок
+// period_start/period_end; для повторяющихся периодов обе колонки NULL.
+func calendarBounds(period string) (sql.NullString, sql.NullString, error) {
+	if domain.IsRecurringPeriod(period) {
+		return sql.NullString{}, sql.NullString{}, nil
+	}
+
+	first, last, err := domain.ParseCalendarPeriod(period)
+	if err != nil {
+		return sql.NullString{}, sql.NullString{}, err
+	}
+
+	return sql.NullString{String: first.Format("2006-01-02"), Valid: true},
+		sql.NullString{String: last.Format("2006-01-02"), Valid: true}, nil
+}
+
+func scanBudget(row rowScanner) (domain.Budget, error) {
+	var budget domain.Budget
+	var thresholds []byte
+
+	err := row.Scan(
+		&budget.ID, &budget.Category, &budget.Limit, &budget.Currency, &budget.Period,
+		&budget.Rollover, &thresholds, &budget.OverspendPolicy, &budget.CreatedAt,
+	)
+	if err != nil {
+		return budget, err
+	}
+
+	if err := json.Unmarshal(thresholds, &budget.WarningThresholds); err != nil {
+		return budget, fmt.Errorf("failed to decode warning thresholds: %w", err)
+	}
+
+	return budget, nil
+}
+
 func NewBudgetRepository(db *sql.DB) domain.BudgetRepository {
 	return &budgetRepository{db: db}
 }
 
-func (r *budgetRepository) Save(ctx context.Context, budget domain.Budget) error {
+// Save создаёт бюджет или обновляет существующий с тем же периодом. Каждое сохранение
+// добавляет версию лимита: у нового бюджета она действует с самого начала, у существующего —
+// с budget.EffectiveFrom (по умолчанию с сегодняшнего дня). Версионируется только лимит:
+// перенос остатка, пороги предупреждений и политика превышения меняются сразу, а валюту
+// существующего бюджета сменить нельзя (ErrBudgetCurrencyChanged).
+func (r *budgetRepository) Save(ctx context.Context, budget *domain.Budget) error {
 	query := `
-		INSERT INTO budgets (category, limit_amount) 
-		VALUES ($1, $2) 
-		ON CONFLICT (category) 
-		DO UPDATE SET limit_amount = EXCLUDED.limit_amount
+		INSERT INTO budgets (category, limit_amount, period, period_start, period_end, rollover, warning_thresholds, overspend_policy, currency) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9) 
+		ON CONFLICT (category, period) 
+		DO UPDATE SET limit_amount = EXCLUDED.limit_amount,
+		              rollover = EXCLUDED.rollover,
+		              warning_thresholds = EXCLUDED.warning_thresholds,
+		              overspend_policy = EXCLUDED.overspend_policy
+		WHERE budgets.currency = EXCLUDED.currency
+		RETURNING id, created_at, xmax = 0
 	`
 
-	_, err := r.db.ExecContext(ctx, query, budget.Category, budget.Limit)
+	period := budget.Period
+	if period == "" {
+		period = domain.PeriodMonthly
+	}
+
+	periodStart, periodEnd, err := calendarBounds(period)
 	if err != nil {
-		return fmt.Errorf("failed to save budget: %w", err)
+		return fmt.Errorf("failed to parse budget period: %w", err)
 	}
 
-	return nil
+	policy := budget.OverspendPolicy
+	if policy == "" {
+		policy = domain.OverspendBlock
+	}
+
+	thresholds := budget.WarningThresholds
+	if thresholds == nil {
+		thresholds = []float64{}
+	}
+	thresholdsJSON, err := json.Marshal(thresholds)
+	if err != nil {
+		return fmt.Errorf("failed to encode warning thresholds: %w", err)
+	}
+
+	// Внутри WithinTransaction бюджет сохраняется в общей транзакции
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var inserted bool
+		err := tx.QueryRowContext(ctx, query,
+			budget.Category, budget.Limit, period, periodStart, periodEnd, budget.Rollover, string(thresholdsJSON), policy,
+			domain.NormalizeCurrency(budget.Currency),
+		).Scan(&budget.ID, &budget.CreatedAt, &inserted)
+		// Строка не возвращается, только если бюджет уже есть и его валюта другая
+		if err == sql.ErrNoRows {
+			return domain.ErrBudgetCurrencyChanged
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save budget: %w", err)
+		}
+
+		var effectiveFrom sql.NullString
+		if !inserted {
+			from := time.Now()
+			if budget.EffectiveFrom != nil {
+				from = *budget.EffectiveFrom
+			}
+			effectiveFrom = sql.NullString{String: from.Format("2006-01-02"), Valid: true}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO budget_versions (budget_id, limit_amount, effective_from)
+			VALUES ($1, $2, $3)
+		`, budget.ID, budget.Limit, effectiveFrom)
+		if err != nil {
+			return fmt.Errorf("failed to save budget version: %w", err)
+		}
+
+		return nil
+	})
 }
 
-func (r *budgetRepository) GetByCategory(ctx context.Context, category string) (*domain.Budget, error) {
+func (r *budgetRepository) GetByCategory(ctx context.Context, category string, date time.Time) (*domain.Budget, error) {
 	query := `
-		SELECT category, limit_amount 
-		FROM budgets 
-		WHERE category = $1
+		SELECT ` + budgetColumns("$2") + ` 
+		FROM budgets b 
+		WHERE b.category = $1
+		  AND (b.period_start IS NULL OR $2::date BETWEEN b.period_start AND b.period_end)
+		ORDER BY b.period_start IS NULL, b.period_end - b.period_start, b.id
+		LIMIT 1
 	`
 
-	var budget domain.Budget
-	err := r.db.QueryRowContext(ctx, query, category).Scan(&budget.Category, &budget.Limit)
+	budget, err := scanBudget(conn(ctx, r.db).QueryRowContext(ctx, query, category, date.Format("2006-01-02")))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -48,19 +169,17 @@ func (r *budgetRepository) GetByCategory(ctx context.Context, category string) (
 		return nil, fmt.Errorf("failed to get budget by category: %w", err)
 	}
 
-	budget.Period = "monthly"
-
 	return &budget, nil
 }
 
 func (r *budgetRepository) List(ctx context.Context) ([]domain.Budget, error) {
 	query := `
-		SELECT category, limit_amount 
-		FROM budgets 
-		ORDER BY category
+		SELECT ` + budgetColumns("CURRENT_DATE") + ` 
+		FROM budgets b 
+		ORDER BY b.category, b.period_start NULLS FIRST, b.period
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query budgets: %w", err)
 	}
@@ -68,13 +187,11 @@ func (r *budgetRepository) List(ctx context.Context) ([]domain.Budget, error) {
 
 	var budgets []domain.Budget
 	for rows.Next() {
-		var budget domain.Budget
-		err := rows.Scan(&budget.Category, &budget.Limit)
+		budget, err := scanBudget(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan budget: %w", err)
 		}
 
-		budget.Period = "monthly"
 		budgets = append(budgets, budget)
 	}
 
@@ -85,6 +202,60 @@ func (r *budgetRepository) List(ctx context.Context) ([]domain.Budget, error) {
 	return budgets, nil
 }
 
+func (r *budgetRepository) LimitAt(ctx context.Context, budgetID int, date time.Time) (domain.Money, error) {
+	query := `SELECT ` + fmt.Sprintf(limitAtQuery, "$2") + ` FROM budgets b WHERE b.id = $1`
+
+	var limit domain.Money
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, budgetID, date.Format("2006-01-02")).Scan(&limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get budget limit: %w", err)
+	}
+
+	return limit, nil
+}
+
+func (r *budgetRepository) History(ctx context.Context, category string) ([]domain.BudgetVersion, error) {
+	query := `
+		SELECT b.id, b.category, b.period, v.limit_amount, v.effective_from, v.created_at
+		FROM budget_versions v
+		JOIN budgets b ON b.id = v.budget_id
+		WHERE b.category = $1
+		ORDER BY b.period_start NULLS FIRST, b.period, v.effective_from NULLS FIRST, v.id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget history: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []domain.BudgetVersion
+	for rows.Next() {
+		var version domain.BudgetVersion
+		var effectiveFrom sql.NullTime
+
+		err := rows.Scan(
+			&version.BudgetID, &version.Category, &version.Period,
+			&version.Limit, &effectiveFrom, &version.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget version: %w", err)
+		}
+
+		if effectiveFrom.Valid {
+			version.EffectiveFrom = &effectiveFrom.Time
+		}
+
+		versions = append(versions, version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating budget history: %w", err)
+	}
+
+	return versions, nil
+}
+
 func (r *budgetRepository) Exists(ctx context.Context, category string) (bool, error) {
 	query := `
 		SELECT EXISTS(
@@ -93,10 +264,86 @@ func (r *budgetRepository) Exists(ctx context.Context, category string) (bool, e
 	`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, category).Scan(&exists)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, category).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check budget existence: %w", err)
 	}
 
 	return exists, nil
 }
+
+func (r *budgetRepository) SaveOverride(ctx context.Context, override *domain.LimitOverride) error {
+	query := `
+		INSERT INTO budget_limit_overrides (budget_id, kind, amount, starts_on, ends_on, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		override.BudgetID,
+		override.Kind,
+		override.Amount,
+		override.StartsOn.Format("2006-01-02"),
+		override.EndsOn.Format("2006-01-02"),
+		override.Reason,
+	).Scan(&override.ID, &override.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save limit override: %w", err)
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) OverridesAt(ctx context.Context, budgetID int, date time.Time) ([]domain.LimitOverride, error) {
+	query := overrideQuery + `
+		WHERE o.budget_id = $1 AND o.starts_on <= $2::date AND o.ends_on >= $2::date
+		ORDER BY o.id
+	`
+
+	return r.queryOverrides(ctx, query, budgetID, date.Format("2006-01-02"))
+}
+
+func (r *budgetRepository) Overrides(ctx context.Context, category string) ([]domain.LimitOverride, error) {
+	query := overrideQuery + `
+		WHERE b.category = $1
+		ORDER BY o.starts_on, o.id
+	`
+
+	return r.queryOverrides(ctx, query, category)
+}
+
+const overrideQuery = `
+		SELECT o.id, o.budget_id, b.category, b.period, o.kind, o.amount,
+		       o.starts_on, o.ends_on, o.reason, o.created_at
+		FROM budget_limit_overrides o
+		JOIN budgets b ON b.id = o.budget_id`
+
+func (r *budgetRepository) queryOverrides(ctx context.Context, query string, args ...any) ([]domain.LimitOverride, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query limit overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []domain.LimitOverride
+	for rows.Next() {
+		var override domain.LimitOverride
+
+		err := rows.Scan(
+			&override.ID, &override.BudgetID, &override.Category, &override.Period,
+			&override.Kind, &override.Amount, &override.StartsOn, &override.EndsOn,
+			&override.Reason, &override.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan limit override: %w", err)
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating limit overrides: %w", err)
+	}
+
+	return overrides, nil
+}
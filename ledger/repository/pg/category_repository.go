@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ledger/domain"
+)
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) domain.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Ensure(ctx context.Context, name string) error {
+	query := `
+		INSERT INTO categories (name, parent_id)
+		VALUES ($1, (SELECT id FROM categories WHERE name = $2))
+		ON CONFLICT (name) DO NOTHING
+	`
+
+	lineage := domain.CategoryLineage(name)
+	for i := len(lineage) - 1; i >= 0; i-- {
+		category := lineage[i]
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, category, domain.CategoryParent(category)); err != nil {
+			return fmt.Errorf("failed to ensure category %s: %w", category, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) List(ctx context.Context) ([]domain.Category, error) {
+	query := `
+		SELECT id, name, parent_id
+		FROM categories
+		ORDER BY name
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []domain.Category
+	for rows.Next() {
+		var category domain.Category
+		var parentID sql.NullInt64
+
+		if err := rows.Scan(&category.ID, &category.Name, &parentID); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+
+		if parentID.Valid {
+			id := int(parentID.Int64)
+			category.ParentID = &id
+		}
+
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+
+	return categories, nil
+}
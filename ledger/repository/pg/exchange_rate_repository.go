@@ -0,0 +1,104 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"ledger/domain"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// noDataFound — SQLSTATE, с которым функция exchange_rate сообщает об отсутствии курса.
+const noDataFound = "P0002"
+
+// rateError превращает ошибку отсутствия курса из базы в domain.ErrExchangeRateNotFound.
+func rateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == noDataFound {
+		return fmt.Errorf("%w: %s", domain.ErrExchangeRateNotFound, pgErr.Message)
+	}
+	return err
+}
+
+type exchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) domain.ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) Save(ctx context.Context, rate domain.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (currency, base_currency, rate_date, rate)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (currency, base_currency, rate_date)
+		DO UPDATE SET rate = EXCLUDED.rate
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		rate.Currency, rate.BaseCurrency, rate.Date.Format("2006-01-02"), rate.Rate,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+func (r *exchangeRateRepository) List(ctx context.Context, currency, baseCurrency string) ([]domain.ExchangeRate, error) {
+	query := `
+		SELECT currency, base_currency, rate_date, rate
+		FROM exchange_rates
+		WHERE ($1 = '' OR currency = $1) AND ($2 = '' OR base_currency = $2)
+		ORDER BY currency, base_currency, rate_date DESC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, currency, baseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []domain.ExchangeRate
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		if err := rows.Scan(&rate.Currency, &rate.BaseCurrency, &rate.Date, &rate.Rate); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+func (r *exchangeRateRepository) Rate(ctx context.Context, from, to string, date time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	// NUMERIC читается строкой: во float64 курс вроде 1.15 теряет точность,
+	// и округление до копейки расходится с convert_amount
+	var value string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT exchange_rate($1, $2, $3::date)::text`,
+		from, to, date.Format("2006-01-02"),
+	).Scan(&value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", rateError(err))
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse exchange rate %s", value)
+	}
+
+	return rate, nil
+}
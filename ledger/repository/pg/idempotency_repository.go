@@ -0,0 +1,134 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ledger/domain"
+	"time"
+)
+
+// idempotencyLease — сколько незавершённый запрос держит ключ. Если процесс упал
+// посреди запроса, ключ освободится по истечении аренды.
+const idempotencyLease = 5 * time.Minute
+
+type idempotencyRepository struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewIdempotencyRepository хранит ключи ttl с момента запроса.
+func NewIdempotencyRepository(db *sql.DB, ttl time.Duration) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db, ttl: ttl}
+}
+
+// Claim занимает ключ или возвращает запись, которая его держит. Если эту запись освободили
+// или удалили между вставкой и чтением, ключ занимается ещё раз; если он снова
+// освобождается на глазах, запрос считается ещё выполняемым.
+func (r *idempotencyRepository) Claim(ctx context.Context, record domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	existing, claimed, err := r.claim(ctx, record)
+	if errors.Is(err, sql.ErrNoRows) {
+		existing, claimed, err = r.claim(ctx, record)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, domain.ErrIdempotencyKeyInProgress
+	}
+	return existing, claimed, err
+}
+
+func (r *idempotencyRepository) claim(ctx context.Context, record domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	// Заодно удаляются чужие истёкшие ключи; свой ключ перезаписывается через ON CONFLICT
+	query := `
+		WITH purged AS (
+			DELETE FROM idempotency_keys WHERE expires_at < NOW() AND key <> $1
+		)
+		INSERT INTO idempotency_keys (key, method, path, fingerprint, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		ON CONFLICT (key) DO UPDATE
+		SET method = EXCLUDED.method, path = EXCLUDED.path, fingerprint = EXCLUDED.fingerprint,
+		    status_code = NULL, headers = NULL, body = NULL,
+		    created_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < NOW() - make_interval(secs => $6))
+		RETURNING key
+	`
+
+	var key string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		record.Key, record.Method, record.Path, record.Fingerprint,
+		r.ttl.Seconds(), idempotencyLease.Seconds(),
+	).Scan(&key)
+	if err == nil {
+		return nil, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	existing, err := r.get(ctx, record.Key)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+func (r *idempotencyRepository) get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT key, method, path, fingerprint, status_code, headers, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+	`
+
+	var record domain.IdempotencyRecord
+	var statusCode sql.NullInt64
+	var headers, body []byte
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, key).Scan(
+		&record.Key, &record.Method, &record.Path, &record.Fingerprint,
+		&statusCode, &headers, &body, &record.CreatedAt, &record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if statusCode.Valid {
+		response := &domain.StoredResponse{StatusCode: int(statusCode.Int64), Body: body}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &response.Header); err != nil {
+				return nil, fmt.Errorf("failed to decode stored headers: %w", err)
+			}
+		}
+		record.Response = response
+	}
+
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, response domain.StoredResponse) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode stored headers: %w", err)
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $2, headers = $3::jsonb, body = $4
+		WHERE key = $1
+	`, key, response.StatusCode, string(headers), response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND status_code IS NULL`, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
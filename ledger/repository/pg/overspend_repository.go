@@ -0,0 +1,98 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ledger/domain"
+	"time"
+)
+
+type overspendRepository struct {
+	db *sql.DB
+}
+
+func NewOverspendRepository(db *sql.DB) domain.OverspendRepository {
+	return &overspendRepository{db: db}
+}
+
+func (r *overspendRepository) Record(ctx context.Context, transactionID int, overspends []domain.Overspend) error {
+	return recordOverspends(ctx, conn(ctx, r.db), transactionID, overspends)
+}
+
+// Replace заменяет записи о превышении транзакции, например после её изменения.
+func (r *overspendRepository) Replace(ctx context.Context, transactionID int, overspends []domain.Overspend) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM overspend_records WHERE expense_id = $1`, transactionID); err != nil {
+			return fmt.Errorf("failed to delete overspend records: %w", err)
+		}
+		return recordOverspends(ctx, tx, transactionID, overspends)
+	})
+}
+
+func recordOverspends(ctx context.Context, db executor, transactionID int, overspends []domain.Overspend) error {
+	query := `
+		INSERT INTO overspend_records (expense_id, budget_category, policy, reason, limit_amount, spent_amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for _, overspend := range overspends {
+		_, err := db.ExecContext(ctx, query,
+			transactionID,
+			overspend.BudgetCategory,
+			overspend.Policy,
+			overspend.Reason,
+			overspend.Limit,
+			overspend.Spent,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record overspend: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *overspendRepository) List(ctx context.Context, from, to time.Time) ([]domain.OverspendRecord, error) {
+	query := `
+		SELECT e.id, e.type, e.amount, e.currency, e.category, e.description, e.date,
+		       o.budget_category, o.policy, o.reason, o.limit_amount, o.spent_amount, o.created_at
+		FROM overspend_records o
+		JOIN expenses e ON e.id = o.expense_id
+		WHERE e.date BETWEEN $1 AND $2 AND e.deleted_at IS NULL
+		ORDER BY e.date DESC, e.id DESC, o.id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overspends: %w", err)
+	}
+	defer rows.Close()
+
+	var records []domain.OverspendRecord
+	for rows.Next() {
+		var record domain.OverspendRecord
+		tx := &record.Transaction
+
+		err := rows.Scan(
+			&tx.ID, &tx.Type, &tx.Amount, &tx.Currency, &tx.Category, &tx.Description, &tx.Date,
+			&record.BudgetCategory, &record.Policy, &record.Reason,
+			&record.Limit, &record.Spent, &record.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan overspend: %w", err)
+		}
+
+		tx.OverBudget = true
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating overspends: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,242 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ledger/domain"
+	"time"
+)
+
+const recurringColumns = `id, type, amount, currency, category, COALESCE(description, ''), tags,
+		       frequency, interval_count, day_of_month, starts_on, ends_on, active, created_at`
+
+type recurringRepository struct {
+	db *sql.DB
+}
+
+func NewRecurringRepository(db *sql.DB) domain.RecurringRepository {
+	return &recurringRepository{db: db}
+}
+
+func scanRecurring(row rowScanner) (domain.RecurringTransaction, error) {
+	var recurring domain.RecurringTransaction
+	var tags []byte
+	var endsOn sql.NullTime
+
+	err := row.Scan(
+		&recurring.ID, &recurring.Type, &recurring.Amount, &recurring.Currency, &recurring.Category,
+		&recurring.Description, &tags, &recurring.Frequency, &recurring.Interval, &recurring.DayOfMonth,
+		&recurring.StartsOn, &endsOn, &recurring.Active, &recurring.CreatedAt,
+	)
+	if err != nil {
+		return recurring, err
+	}
+
+	if err := json.Unmarshal(tags, &recurring.Tags); err != nil {
+		return recurring, fmt.Errorf("failed to decode tags: %w", err)
+	}
+	if endsOn.Valid {
+		recurring.EndsOn = &endsOn.Time
+	}
+
+	return recurring, nil
+}
+
+func (r *recurringRepository) Create(ctx context.Context, recurring *domain.RecurringTransaction) error {
+	query := `
+		INSERT INTO recurring_transactions
+			(type, amount, currency, category, description, tags, frequency, interval_count, day_of_month, starts_on, ends_on)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, $9, $10, $11)
+		RETURNING id, active, created_at
+	`
+
+	tags := recurring.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode tags: %w", err)
+	}
+
+	var endsOn sql.NullString
+	if recurring.EndsOn != nil {
+		endsOn = sql.NullString{String: recurring.EndsOn.Format("2006-01-02"), Valid: true}
+	}
+
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
+		recurring.Type,
+		recurring.Amount,
+		recurring.Currency,
+		recurring.Category,
+		recurring.Description,
+		string(tagsJSON),
+		recurring.Frequency,
+		recurring.Interval,
+		recurring.DayOfMonth,
+		recurring.StartsOn.Format("2006-01-02"),
+		endsOn,
+	).Scan(&recurring.ID, &recurring.Active, &recurring.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRepository) List(ctx context.Context) ([]domain.RecurringTransaction, error) {
+	return r.query(ctx, `SELECT `+recurringColumns+` FROM recurring_transactions ORDER BY id`)
+}
+
+func (r *recurringRepository) Active(ctx context.Context) ([]domain.RecurringTransaction, error) {
+	return r.query(ctx, `SELECT `+recurringColumns+` FROM recurring_transactions WHERE active ORDER BY id`)
+}
+
+func (r *recurringRepository) query(ctx context.Context, query string) ([]domain.RecurringTransaction, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurring transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var recurrings []domain.RecurringTransaction
+	for rows.Next() {
+		recurring, err := scanRecurring(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recurring transaction: %w", err)
+		}
+		recurrings = append(recurrings, recurring)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recurring transactions: %w", err)
+	}
+
+	return recurrings, nil
+}
+
+func (r *recurringRepository) Deactivate(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE recurring_transactions SET active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate recurring transaction: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to deactivate recurring transaction: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrRecurringNotFound
+	}
+
+	return nil
+}
+
+func (r *recurringRepository) OccurrenceDates(ctx context.Context, recurringID int) ([]time.Time, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT due_on FROM recurring_occurrences
+		WHERE recurring_id = $1 AND status <> 'pending'
+		ORDER BY due_on
+	`, recurringID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occurrence dates: %w", err)
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, fmt.Errorf("failed to scan occurrence date: %w", err)
+		}
+		dates = append(dates, date)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating occurrence dates: %w", err)
+	}
+
+	return dates, nil
+}
+
+func (r *recurringRepository) ClaimOccurrence(ctx context.Context, recurringID int, dueOn time.Time) (int, bool, error) {
+	query := `
+		INSERT INTO recurring_occurrences (recurring_id, due_on)
+		VALUES ($1, $2)
+		ON CONFLICT (recurring_id, due_on) DO UPDATE SET created_at = now()
+		WHERE recurring_occurrences.status = 'pending'
+		RETURNING id
+	`
+
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, recurringID, dueOn.Format("2006-01-02")).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to claim occurrence: %w", err)
+	}
+
+	return id, true, nil
+}
+
+func (r *recurringRepository) CompleteOccurrence(ctx context.Context, occurrence domain.Occurrence) error {
+	var reason sql.NullString
+	if occurrence.Error != "" {
+		reason = sql.NullString{String: occurrence.Error, Valid: true}
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE recurring_occurrences
+		SET status = $2, expense_id = $3, error = $4
+		WHERE id = $1
+	`, occurrence.ID, occurrence.Status, occurrence.TransactionID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to complete occurrence: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRepository) Occurrences(ctx context.Context, recurringID int, status string) ([]domain.Occurrence, error) {
+	query := `
+		SELECT id, recurring_id, due_on, status, expense_id, COALESCE(error, ''), created_at
+		FROM recurring_occurrences
+		WHERE ($1 = 0 OR recurring_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY due_on DESC, id DESC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, recurringID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	var occurrences []domain.Occurrence
+	for rows.Next() {
+		var occurrence domain.Occurrence
+		var expenseID sql.NullInt64
+
+		err := rows.Scan(
+			&occurrence.ID, &occurrence.RecurringID, &occurrence.DueOn, &occurrence.Status,
+			&expenseID, &occurrence.Error, &occurrence.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan occurrence: %w", err)
+		}
+
+		if expenseID.Valid {
+			id := int(expenseID.Int64)
+			occurrence.TransactionID = &id
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating occurrences: %w", err)
+	}
+
+	return occurrences, nil
+}
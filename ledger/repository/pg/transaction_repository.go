@@ -3,8 +3,10 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"ledger/domain"
+	"strings"
 	"time"
 )
 
@@ -16,27 +18,84 @@ func NewTransactionRepository(db *sql.DB) domain.TransactionRepository {
 	return &transactionRepository{db: db}
 }
 
-func (r *transactionRepository) Create(ctx context.Context, transaction domain.Transaction) (int, error) {
-	var id int
+// transactionColumns перечисляет колонки для scanTransaction; удалённые транзакции
+// отбираются условием deleted_at IS NULL в самих запросах.
+const transactionColumns = `e.id, e.type, e.amount, e.currency, e.category, COALESCE(e.description, ''), e.date,
+		       EXISTS (SELECT 1 FROM overspend_records o WHERE o.expense_id = e.id),
+		       e.reverses_id, (SELECT r.id FROM expenses r WHERE r.reverses_id = e.id), e.split_id,
+		       ` + tagsColumn
+
+// tagsColumn выбирает метки транзакции e как JSON-массив, отсортированный по имени.
+const tagsColumn = `COALESCE((
+		SELECT json_agg(t.name ORDER BY t.name)
+		FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+		WHERE et.expense_id = e.id
+	), '[]')`
+
+// tagCondition отбирает транзакции e хотя бы с одной из меток из параметра param;
+// пустой массив меток не ограничивает выборку.
+func tagCondition(param string) string {
+	return `(cardinality(` + param + `::text[]) = 0 OR EXISTS (
+			SELECT 1 FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+			WHERE et.expense_id = e.id AND t.name = ANY(` + param + `)
+		))`
+}
+
+func scanTransaction(row rowScanner) (domain.Transaction, error) {
+	var tx domain.Transaction
+	var reversesID, reversedByID, splitID sql.NullInt64
+	var tags []byte
 
-	date := transaction.Date
-	if date.IsZero() {
-		date = time.Now()
+	err := row.Scan(
+		&tx.ID, &tx.Type, &tx.Amount, &tx.Currency, &tx.Category, &tx.Description, &tx.Date,
+		&tx.OverBudget, &reversesID, &reversedByID, &splitID, &tags,
+	)
+	if err != nil {
+		return tx, err
 	}
 
-	query := `
-		INSERT INTO expenses (amount, category, description, date) 
-		VALUES ($1, $2, $3, $4) 
-		RETURNING id
-	`
+	if err := json.Unmarshal(tags, &tx.Tags); err != nil {
+		return tx, fmt.Errorf("failed to decode tags: %w", err)
+	}
 
-	err := r.db.QueryRowContext(ctx, query,
-		transaction.Amount,
-		transaction.Category,
-		transaction.Description,
-		date.Format("2006-01-02 15:04:05"),
-	).Scan(&id)
+	if reversesID.Valid {
+		id := int(reversesID.Int64)
+		tx.ReversesID = &id
+	}
+	if reversedByID.Valid {
+		id := int(reversedByID.Int64)
+		tx.ReversedByID = &id
+	}
+	if splitID.Valid {
+		id := int(splitID.Int64)
+		tx.SplitID = &id
+	}
 
+	return tx, nil
+}
+
+// Create сохраняет транзакцию вместе с записью журнала изменений.
+func (r *transactionRepository) Create(ctx context.Context, transaction domain.Transaction, overspends ...domain.Overspend) (int, error) {
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+
+	var id int
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		id, err = insertExpense(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+		if err := saveTags(ctx, tx, id, transaction.Tags); err != nil {
+			return err
+		}
+		// Транзакция сверх лимита сохраняется только вместе с отметкой о превышении
+		if err := recordOverspends(ctx, tx, id, overspends); err != nil {
+			return err
+		}
+		return insertAudit(ctx, tx, id, domain.ChangeCreate, nil, domain.SnapshotOf(transaction))
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to create transaction: %w", err)
 	}
@@ -44,14 +103,67 @@ func (r *transactionRepository) Create(ctx context.Context, transaction domain.T
 	return id, nil
 }
 
-func (r *transactionRepository) List(ctx context.Context) ([]domain.Transaction, error) {
+// List строит запрос по фильтру: условия добавляются только для заданных полей,
+// а страница продолжается сравнением (ключ сортировки, id) с курсором.
+func (r *transactionRepository) List(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	var args []any
+	param := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"e.deleted_at IS NULL"}
+	if filter.Category != "" {
+		conditions = append(conditions, categoryTreeCondition(param(filter.Category)))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "e.date >= "+param(filter.From.Format("2006-01-02 15:04:05")))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "e.date <= "+param(filter.To.Format("2006-01-02 15:04:05")))
+	}
+	if filter.MinAmount != nil {
+		conditions = append(conditions, "e.amount >= "+param(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, "e.amount <= "+param(*filter.MaxAmount))
+	}
+	if filter.Description != "" {
+		conditions = append(conditions, "e.description ILIKE '%' || "+param(escapeLike(filter.Description))+" || '%'")
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, tagCondition(param(filter.Tags)))
+	}
+
+	key, direction, compare := "e.date", "DESC", "<"
+	switch filter.Sort {
+	case domain.SortDateAsc:
+		direction, compare = "ASC", ">"
+	case domain.SortAmountDesc:
+		key = "e.amount"
+	case domain.SortAmountAsc:
+		key, direction, compare = "e.amount", "ASC", ">"
+	}
+
+	if filter.After != nil {
+		var after any = filter.After.Date.Format("2006-01-02 15:04:05.999999")
+		if key == "e.amount" {
+			after = filter.After.Amount
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, e.id) %s (%s, %s)",
+			key, compare, param(after), param(filter.After.ID)))
+	}
+
 	query := `
-		SELECT id, amount, category, description, date 
-		FROM expenses 
-		ORDER BY date DESC, id DESC
-	`
+		SELECT ` + transactionColumns + `
+		FROM expenses e 
+		WHERE ` + strings.Join(conditions, "\n\t\t  AND ") + `
+		ORDER BY ` + key + ` ` + direction + `, e.id ` + direction
+	if filter.Limit > 0 {
+		query += "\n\t\tLIMIT " + param(filter.Limit)
+	}
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -59,20 +171,11 @@ func (r *transactionRepository) List(ctx context.Context) ([]domain.Transaction,
 
 	var transactions []domain.Transaction
 	for rows.Next() {
-		var tx domain.Transaction
-		var dateStr string
-
-		err := rows.Scan(&tx.ID, &tx.Amount, &tx.Category, &tx.Description, &dateStr)
+		tx, err := scanTransaction(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
 		}
 
-		if date, err := time.Parse("2006-01-02 15:04:05", dateStr); err == nil {
-			tx.Date = date
-		} else {
-			tx.Date = time.Now()
-		}
-
 		transactions = append(transactions, tx)
 	}
 
@@ -83,74 +186,623 @@ func (r *transactionRepository) List(ctx context.Context) ([]domain.Transaction,
 	return transactions, nil
 }
 
-func (r *transactionRepository) GetTotalByCategory(ctx context.Context, category string) (float64, error) {
+// categoryTreeCondition отбирает транзакции e категории из параметра param и всех её потомков.
+func categoryTreeCondition(param string) string {
+	return `(e.category = ` + param + ` OR e.category IN (
+			WITH RECURSIVE tree AS (
+				SELECT id, name FROM categories WHERE name = ` + param + `
+				UNION ALL
+				SELECT c.id, c.name FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT name FROM tree
+		))`
+}
+
+// Search сопоставляет запрос с поисковым вектором описания на русском и английском;
+// ранжирование и выделение совпадений делаются в базе.
+func (r *transactionRepository) Search(ctx context.Context, search domain.TransactionSearch) ([]domain.SearchResult, error) {
+	args := []any{search.Query}
+	param := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"e.deleted_at IS NULL", "e.search_vector @@ q.query"}
+	if search.Category != "" {
+		conditions = append(conditions, categoryTreeCondition(param(search.Category)))
+	}
+	if search.From != nil {
+		conditions = append(conditions, "e.date >= "+param(search.From.Format("2006-01-02 15:04:05")))
+	}
+	if search.To != nil {
+		conditions = append(conditions, "e.date <= "+param(search.To.Format("2006-01-02 15:04:05")))
+	}
+
 	query := `
-		SELECT COALESCE(SUM(amount), 0) 
+		SELECT ` + transactionColumns + `,
+		       ts_rank(e.search_vector, q.query),
+		       ` + headlineColumn + `
+		FROM expenses e,
+		     (SELECT websearch_to_tsquery('russian', $1) AS russian,
+		             websearch_to_tsquery('english', $1) AS english,
+		             websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query) q
+		WHERE ` + strings.Join(conditions, "\n\t\t  AND ") + `
+		ORDER BY ts_rank(e.search_vector, q.query) DESC, e.date DESC, e.id DESC
+		LIMIT ` + param(search.Limit)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []domain.SearchResult
+	for rows.Next() {
+		var result domain.SearchResult
+		tx, err := scanTransaction(rankedRow{rows, &result.Rank, &result.Headline})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		result.Transaction = tx
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// headlineColumn выделяет совпадения в описании. Каждая конфигурация выделяет слова своего
+// запроса, так как стеммеры разные: сначала русская, затем английская поверх её разметки
+// (теги <b> парсер оставляет как есть). Описание заранее экранируется для HTML, чтобы
+// в нём не осталось разметки, кроме выделения.
+const headlineColumn = `replace(replace(
+		           ts_headline('english',
+		               ts_headline('russian',
+		                   replace(replace(replace(replace(COALESCE(e.description, ''),
+		                       '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+		                   q.russian, 'StartSel=<b>, StopSel=</b>, HighlightAll=true'),
+		               q.english, 'StartSel=<b>, StopSel=</b>, HighlightAll=true'),
+		       '<b><b>', '<b>'), '</b></b>', '</b>')`
+
+// rankedRow дописывает к колонкам транзакции релевантность и выделенное описание.
+type rankedRow struct {
+	rows     *sql.Rows
+	rank     *float64
+	headline *string
+}
+
+func (r rankedRow) Scan(dest ...any) error {
+	return r.rows.Scan(append(dest, r.rank, r.headline)...)
+}
+
+// escapeLike экранирует символы шаблона LIKE, чтобы подстрока искалась буквально.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *transactionRepository) Categories(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT DISTINCT category
+		FROM expenses
+		WHERE deleted_at IS NULL
+		ORDER BY category
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []string
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *transactionRepository) GetTotalByCategory(ctx context.Context, category string) (domain.Money, error) {
+	query := `
+		SELECT COALESCE(SUM(convert_amount(amount, currency, $2, date::date)), 0) 
 		FROM expenses 
-		WHERE category = $1
+		WHERE category = $1 AND deleted_at IS NULL AND type <> 'income'
 	`
 
-	var total float64
-	err := r.db.QueryRowContext(ctx, query, category).Scan(&total)
+	var total domain.Money
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, category, domain.BaseCurrency).Scan(&total)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get total by category: %w", err)
+		return 0, fmt.Errorf("failed to get total by category: %w", rateError(err))
 	}
 
 	return total, nil
 }
 
+// GetByID возвращает транзакцию или nil, если её нет или она удалена.
 func (r *transactionRepository) GetByID(ctx context.Context, id int) (*domain.Transaction, error) {
 	query := `
-		SELECT id, amount, category, description, date 
-		FROM expenses 
-		WHERE id = $1
+		SELECT ` + transactionColumns + `
+		FROM expenses e 
+		WHERE e.id = $1 AND e.deleted_at IS NULL
 	`
 
-	var tx domain.Transaction
-	var dateStr string
+	tx, err := scanTransaction(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction by id: %w", err)
+	}
+
+	return &tx, nil
+}
+
+// GetForUpdate возвращает транзакцию, блокируя её строку до конца транзакции БД;
+// вызывается внутри WithinTransaction. Транзакция читается отдельным запросом после блокировки:
+// сторно не меняет строку исходной транзакции, и подзапрос в том же SELECT ... FOR UPDATE
+// не увидел бы сторнирующую запись, зафиксированную, пока строка ждала блокировки.
+func (r *transactionRepository) GetForUpdate(ctx context.Context, id int) (*domain.Transaction, error) {
+	var locked int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id FROM expenses WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+	).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock transaction: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Update сохраняет новые значения транзакции и записывает в журнал прежние и новые.
+func (r *transactionRepository) Update(ctx context.Context, transaction domain.Transaction) error {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := lockExpense(ctx, tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE expenses
+			SET amount = $2, category = $3, description = $4, date = $5, type = $6, currency = $7
+			WHERE id = $1
+		`
+
+		_, err = tx.ExecContext(ctx, query,
+			transaction.ID,
+			transaction.Amount,
+			transaction.Category,
+			transaction.Description,
+			transaction.Date.Format("2006-01-02 15:04:05"),
+			transaction.Type,
+			transaction.Currency,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := saveTags(ctx, tx, transaction.ID, transaction.Tags); err != nil {
+			return err
+		}
+
+		return insertAudit(ctx, tx, transaction.ID, domain.ChangeUpdate, before, domain.SnapshotOf(transaction))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete помечает транзакцию удалённой; строка остаётся для журнала изменений.
+func (r *transactionRepository) Delete(ctx context.Context, id int) error {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := lockExpense(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE expenses SET deleted_at = now() WHERE id = $1`, id); err != nil {
+			return err
+		}
+
+		return insertAudit(ctx, tx, id, domain.ChangeDelete, before, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Reverse сохраняет сторнирующую запись reversal для транзакции reversal.ReversesID.
+func (r *transactionRepository) Reverse(ctx context.Context, reversal domain.Transaction) (int, error) {
+	var id int
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := lockExpense(ctx, tx, *reversal.ReversesID)
+		if err != nil {
+			return err
+		}
+
+		id, err = insertExpense(ctx, tx, reversal)
+		if err != nil {
+			return err
+		}
+		if err := saveTags(ctx, tx, id, reversal.Tags); err != nil {
+			return err
+		}
+
+		if err := insertAudit(ctx, tx, id, domain.ChangeCreate, nil, domain.SnapshotOf(reversal)); err != nil {
+			return err
+		}
+		return insertAudit(ctx, tx, *reversal.ReversesID, domain.ChangeReverse, before, nil)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reverse transaction: %w", err)
+	}
+
+	return id, nil
+}
+
+// CreateSplit сохраняет платёж и его строки; каждая строка получает запись журнала изменений.
+func (r *transactionRepository) CreateSplit(ctx context.Context, split *domain.Split) error {
+	if split.Date.IsZero() {
+		split.Date = time.Now()
+	}
+
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO transaction_splits (amount, currency, description, date)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`
+
+		err := tx.QueryRowContext(ctx, query,
+			split.Amount,
+			split.Currency,
+			split.Description,
+			split.Date.Format("2006-01-02 15:04:05"),
+		).Scan(&split.ID)
+		if err != nil {
+			return err
+		}
+
+		for i := range split.Lines {
+			line := &split.Lines[i]
+			line.SplitID = &split.ID
+			line.Date = split.Date
+
+			line.ID, err = insertExpense(ctx, tx, *line)
+			if err != nil {
+				return err
+			}
+			if err := saveTags(ctx, tx, line.ID, line.Tags); err != nil {
+				return err
+			}
+			if err := insertAudit(ctx, tx, line.ID, domain.ChangeCreate, nil, domain.SnapshotOf(*line)); err != nil {
+				return err
+			}
+		}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&tx.ID, &tx.Amount, &tx.Category, &tx.Description, &dateStr,
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create split: %w", err)
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) GetSplit(ctx context.Context, id int) (*domain.Split, error) {
+	query := `
+		SELECT id, amount, currency, COALESCE(description, ''), date
+		FROM transaction_splits
+		WHERE id = $1
+	`
+
+	var split domain.Split
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&split.ID, &split.Amount, &split.Currency, &split.Description, &split.Date,
 	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get split: %w", err)
+	}
 
+	linesQuery := `
+		SELECT ` + transactionColumns + `
+		FROM expenses e
+		WHERE e.split_id = $1 AND e.deleted_at IS NULL
+		ORDER BY e.id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, linesQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query split lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan split line: %w", err)
+		}
+		split.Lines = append(split.Lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating split lines: %w", err)
+	}
+
+	// Платёж, все строки которого удалены, считается удалённым
+	if len(split.Lines) == 0 {
+		return nil, nil
+	}
+
+	return &split, nil
+}
+
+// DeleteSplit мягко удаляет все строки платежа, записывая каждое удаление в журнал.
+func (r *transactionRepository) DeleteSplit(ctx context.Context, id int) error {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id FROM expenses
+			WHERE split_id = $1 AND deleted_at IS NULL
+			ORDER BY id
+		`, id)
+		if err != nil {
+			return err
+		}
+
+		var lineIDs []int
+		for rows.Next() {
+			var lineID int
+			if err := rows.Scan(&lineID); err != nil {
+				rows.Close()
+				return err
+			}
+			lineIDs = append(lineIDs, lineID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(lineIDs) == 0 {
+			return domain.ErrSplitNotFound
+		}
+
+		for _, lineID := range lineIDs {
+			before, err := lockExpense(ctx, tx, lineID)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE expenses SET deleted_at = now() WHERE id = $1`, lineID); err != nil {
+				return err
+			}
+			if err := insertAudit(ctx, tx, lineID, domain.ChangeDelete, before, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete split: %w", err)
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) Changes(ctx context.Context, id int) ([]domain.TransactionChange, error) {
+	query := `
+		SELECT id, expense_id, action, old_values, new_values, created_at
+		FROM expense_audit
+		WHERE expense_id = $1
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []domain.TransactionChange
+	for rows.Next() {
+		var change domain.TransactionChange
+		var before, after []byte
+
+		err := rows.Scan(&change.ID, &change.TransactionID, &change.Action, &before, &after, &change.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction change: %w", err)
+		}
+
+		if change.Before, err = decodeSnapshot(before); err != nil {
+			return nil, err
+		}
+		if change.After, err = decodeSnapshot(after); err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+func insertExpense(ctx context.Context, tx *sql.Tx, transaction domain.Transaction) (int, error) {
+	query := `
+		INSERT INTO expenses (amount, category, description, date, reverses_id, type, currency, split_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+		RETURNING id
+	`
+
+	var id int
+	err := tx.QueryRowContext(ctx, query,
+		transaction.Amount,
+		transaction.Category,
+		transaction.Description,
+		transaction.Date.Format("2006-01-02 15:04:05"),
+		transaction.ReversesID,
+		transaction.Type,
+		transaction.Currency,
+		transaction.SplitID,
+	).Scan(&id)
+
+	return id, err
+}
+
+// lockExpense блокирует строку неудалённой транзакции до конца транзакции БД
+// и возвращает её текущие значения для журнала.
+func lockExpense(ctx context.Context, tx *sql.Tx, id int) (*domain.TransactionSnapshot, error) {
+	query := `
+		SELECT e.type, e.amount, e.currency, e.category, COALESCE(e.description, ''), e.date, ` + tagsColumn + `
+		FROM expenses e
+		WHERE e.id = $1 AND e.deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var snapshot domain.TransactionSnapshot
+	var tags []byte
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&snapshot.Type, &snapshot.Amount, &snapshot.Currency, &snapshot.Category, &snapshot.Description, &snapshot.Date, &tags,
+	)
 	if err == sql.ErrNoRows {
+		return nil, domain.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(tags, &snapshot.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode tags: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// saveTags заменяет метки транзакции, создавая недостающие.
+func saveTags(ctx context.Context, tx *sql.Tx, expenseID int, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM expense_tags WHERE expense_id = $1`, expenseID); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tags (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+	`, tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO expense_tags (expense_id, tag_id)
+		SELECT $1, id FROM tags WHERE name = ANY($2)
+	`, expenseID, tags)
+	return err
+}
+
+// tagsParam заменяет nil пустым массивом: cardinality(NULL) в tagCondition даёт NULL.
+func tagsParam(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func insertAudit(ctx context.Context, tx *sql.Tx, expenseID int, action string, before, after *domain.TransactionSnapshot) error {
+	oldValues, err := encodeSnapshot(before)
+	if err != nil {
+		return err
+	}
+	newValues, err := encodeSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO expense_audit (expense_id, action, old_values, new_values)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err = tx.ExecContext(ctx, query, expenseID, action, oldValues, newValues)
+	return err
+}
+
+func encodeSnapshot(snapshot *domain.TransactionSnapshot) (any, error) {
+	if snapshot == nil {
 		return nil, nil
 	}
+
+	data, err := json.Marshal(snapshot)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction by id: %w", err)
+		return nil, fmt.Errorf("failed to encode transaction snapshot: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func decodeSnapshot(data []byte) (*domain.TransactionSnapshot, error) {
+	if data == nil {
+		return nil, nil
 	}
 
-	if date, err := time.Parse("2006-01-02 15:04:05", dateStr); err == nil {
-		tx.Date = date
-	} else {
-		tx.Date = time.Now()
+	var snapshot domain.TransactionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction snapshot: %w", err)
 	}
 
-	return &tx, nil
+	return &snapshot, nil
 }
 
-func (r *transactionRepository) GetSpendingByPeriod(ctx context.Context, from, to time.Time) (domain.SpendingSummary, error) {
+func (r *transactionRepository) GetSpendingByPeriod(ctx context.Context, from, to time.Time, currency string) (domain.SpendingSummary, error) {
 	query := `
-		SELECT category, COALESCE(SUM(amount), 0) as total
+		SELECT category, COALESCE(SUM(convert_amount(amount, currency, $3, date::date)), 0) as total
 		FROM expenses 
-		WHERE date BETWEEN $1 AND $2
+		WHERE date BETWEEN $1 AND $2 AND deleted_at IS NULL AND type <> 'income'
 		GROUP BY category
 		ORDER BY total DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query,
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
 		from.Format("2006-01-02 15:04:05"),
 		to.Format("2006-01-02 15:04:05"),
+		currency,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query spending by period: %w", err)
+		return nil, fmt.Errorf("failed to query spending by period: %w", rateError(err))
 	}
 	defer rows.Close()
 
 	summary := make(domain.SpendingSummary)
 	for rows.Next() {
 		var category string
-		var total float64
+		var total domain.Money
 
 		err := rows.Scan(&category, &total)
 		if err != nil {
@@ -161,29 +813,207 @@ func (r *transactionRepository) GetSpendingByPeriod(ctx context.Context, from, t
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating spending data: %w", err)
+		return nil, fmt.Errorf("error iterating spending data: %w", rateError(err))
 	}
 
 	return summary, nil
 }
 
-func (r *transactionRepository) GetSpendingByCategoryAndPeriod(ctx context.Context, category string, from, to time.Time) (float64, error) {
+func (r *transactionRepository) GetSpendingByCategoryAndPeriod(ctx context.Context, category string, from, to time.Time, currency string, tags []string) (domain.Money, error) {
 	query := `
-		SELECT COALESCE(SUM(amount), 0) 
+		SELECT COALESCE(SUM(convert_amount(e.amount, e.currency, $4, e.date::date)), 0) 
+		FROM expenses e 
+		WHERE e.category = $1 AND e.date BETWEEN $2 AND $3 AND e.deleted_at IS NULL AND e.type <> 'income'
+		  AND ` + tagCondition("$5") + `
+	`
+
+	var total domain.Money
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		category,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+		currency,
+		tagsParam(tags),
+	).Scan(&total)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spending for category %s: %w", category, rateError(err))
+	}
+
+	return total, nil
+}
+
+func (r *transactionRepository) GetSpendingByCategoryTreeAndPeriod(ctx context.Context, category string, from, to time.Time, currency string) (domain.Money, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, name FROM categories WHERE name = $1
+			UNION ALL
+			SELECT c.id, c.name FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT COALESCE(SUM(convert_amount(amount, currency, $4, date::date)), 0) 
 		FROM expenses 
-		WHERE category = $1 AND date BETWEEN $2 AND $3
+		WHERE (category = $1 OR category IN (SELECT name FROM tree))
+		  AND date BETWEEN $2 AND $3
+		  AND deleted_at IS NULL AND type <> 'income'
 	`
 
-	var total float64
-	err := r.db.QueryRowContext(ctx, query,
+	var total domain.Money
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		category,
 		from.Format("2006-01-02 15:04:05"),
 		to.Format("2006-01-02 15:04:05"),
+		currency,
+	).Scan(&total)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spending for category tree %s: %w", category, rateError(err))
+	}
+
+	return total, nil
+}
+
+func (r *transactionRepository) GetSpendingByCategoriesAndPeriod(ctx context.Context, categories []string, from, to time.Time, currency string) (domain.Money, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, name FROM categories WHERE name = ANY($1)
+			UNION
+			SELECT c.id, c.name FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT COALESCE(SUM(convert_amount(amount, currency, $4, date::date)), 0) 
+		FROM expenses 
+		WHERE (category = ANY($1) OR category IN (SELECT name FROM tree))
+		  AND date BETWEEN $2 AND $3
+		  AND deleted_at IS NULL AND type <> 'income'
+	`
+
+	var total domain.Money
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		categories,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+		currency,
 	).Scan(&total)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to get spending for category %s: %w", category, err)
+		return 0, fmt.Errorf("failed to get spending for categories: %w", rateError(err))
 	}
 
 	return total, nil
 }
+
+func (r *transactionRepository) GetSpendingByPeriods(ctx context.Context, categories []string, period string, from, to time.Time, currency string) ([]domain.PeriodSpending, error) {
+	var unit string
+	switch period {
+	case domain.PeriodDaily:
+		unit = "day"
+	case domain.PeriodWeekly:
+		unit = "week"
+	default:
+		unit = "month"
+	}
+
+	// Фильтр по категории и дате покрывается индексом idx_expenses_category_date
+	query := `
+		SELECT category, date_trunc($4, date)::date AS period_start,
+		       SUM(convert_amount(amount, currency, $5, date::date))
+		FROM expenses
+		WHERE category = ANY($1)
+		  AND date BETWEEN $2 AND $3
+		  AND deleted_at IS NULL AND type <> 'income'
+		GROUP BY category, period_start
+		ORDER BY category, period_start
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		categories,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+		unit,
+		currency,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending by periods: %w", rateError(err))
+	}
+	defer rows.Close()
+
+	var spendings []domain.PeriodSpending
+	for rows.Next() {
+		var spending domain.PeriodSpending
+		if err := rows.Scan(&spending.Category, &spending.PeriodStart, &spending.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan spending by periods: %w", err)
+		}
+		spendings = append(spendings, spending)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating spending by periods: %w", rateError(err))
+	}
+
+	return spendings, nil
+}
+
+func (r *transactionRepository) GetSpendingByTags(ctx context.Context, from, to time.Time, currency string, tags []string) ([]domain.TagSpending, error) {
+	query := `
+		SELECT t.name, COALESCE(SUM(convert_amount(e.amount, e.currency, $3, e.date::date)), 0) AS total, COUNT(*)
+		FROM expenses e
+		JOIN expense_tags et ON et.expense_id = e.id
+		JOIN tags t ON t.id = et.tag_id
+		WHERE e.date BETWEEN $1 AND $2 AND e.deleted_at IS NULL AND e.type <> 'income'
+		  AND (cardinality($4::text[]) = 0 OR t.name = ANY($4))
+		GROUP BY t.name
+		ORDER BY total DESC, t.name
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+		currency,
+		tagsParam(tags),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending by tags: %w", rateError(err))
+	}
+	defer rows.Close()
+
+	var spendings []domain.TagSpending
+	for rows.Next() {
+		var spending domain.TagSpending
+		if err := rows.Scan(&spending.Tag, &spending.Amount, &spending.Transactions); err != nil {
+			return nil, fmt.Errorf("failed to scan spending by tags: %w", err)
+		}
+		spendings = append(spendings, spending)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating spending by tags: %w", rateError(err))
+	}
+
+	return spendings, nil
+}
+
+func (r *transactionRepository) GetCashFlow(ctx context.Context, from, to time.Time, currency string) (domain.CashFlow, error) {
+	query := `
+		WITH converted AS (
+			SELECT type, convert_amount(amount, currency, $3, date::date) AS amount
+			FROM expenses
+			WHERE date BETWEEN $1 AND $2 AND deleted_at IS NULL
+		)
+		SELECT COALESCE(SUM(amount) FILTER (WHERE type = 'income'), 0),
+		       COALESCE(SUM(amount) FILTER (WHERE type = 'expense'), 0),
+		       COALESCE(-SUM(amount) FILTER (WHERE type = 'refund'), 0)
+		FROM converted
+	`
+
+	cashFlow := domain.CashFlow{Currency: currency}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+		currency,
+	).Scan(&cashFlow.Income, &cashFlow.Expenses, &cashFlow.Refunds)
+
+	if err != nil {
+		return cashFlow, fmt.Errorf("failed to get cash flow: %w", rateError(err))
+	}
+
+	return cashFlow, nil
+}
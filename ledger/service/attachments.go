@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"ledger/domain"
+	"log"
+	"strings"
+)
+
+// UploadAttachment сохраняет файл в хранилище и привязывает его к транзакции.
+// Удалённые транзакции считаются несуществующими.
+func (s *ledgerService) UploadAttachment(ctx context.Context, req domain.UploadAttachmentRequest) (*domain.AttachmentResponse, error) {
+	if req.Content == nil {
+		return nil, fmt.Errorf("%w: file is required", domain.ErrValidation)
+	}
+	if strings.TrimSpace(req.ContentType) == "" {
+		return nil, fmt.Errorf("%w: content type is required", domain.ErrValidation)
+	}
+
+	if err := s.requireTransaction(ctx, req.TransactionID); err != nil {
+		return nil, err
+	}
+
+	staged, err := s.blobStore.Stage(ctx, req.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	defer staged.Discard()
+
+	attachment := domain.Attachment{
+		TransactionID: req.TransactionID,
+		Filename:      domain.SanitizeFilename(req.Filename),
+		ContentType:   req.ContentType,
+		Size:          staged.Size(),
+		SHA256:        staged.SHA256(),
+	}
+
+	// Содержимое сохраняется и получает ссылку под блокировкой хеша: иначе удаление
+	// последнего вложения с тем же содержимым может стереть файл между ними
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.transactor.Lock(ctx, blobLockKey(attachment.SHA256)); err != nil {
+			return fmt.Errorf("failed to lock attachment content: %w", err)
+		}
+		if err := staged.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to store attachment: %w", err)
+		}
+		if err := s.attachmentRepo.Create(ctx, &attachment); err != nil {
+			return fmt.Errorf("failed to create attachment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.releaseBlob(ctx, attachment.SHA256)
+		return nil, err
+	}
+
+	response := domain.AttachmentResponseFromEntity(attachment)
+	return &response, nil
+}
+
+func (s *ledgerService) ListAttachments(ctx context.Context, transactionID int) ([]domain.AttachmentResponse, error) {
+	if err := s.requireTransaction(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.List(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	responses := make([]domain.AttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		responses[i] = domain.AttachmentResponseFromEntity(attachment)
+	}
+
+	return responses, nil
+}
+
+func (s *ledgerService) OpenAttachment(ctx context.Context, transactionID, id int) (*domain.AttachmentResponse, io.ReadCloser, error) {
+	attachment, err := s.getAttachment(ctx, transactionID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobStore.Open(ctx, attachment.SHA256)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+
+	response := domain.AttachmentResponseFromEntity(*attachment)
+	return &response, content, nil
+}
+
+// DeleteAttachment удаляет вложение; содержимое удаляется, когда на него не осталось ссылок.
+func (s *ledgerService) DeleteAttachment(ctx context.Context, transactionID, id int) error {
+	attachment, err := s.getAttachment(ctx, transactionID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+		return err
+	}
+
+	s.releaseBlob(ctx, attachment.SHA256)
+	return nil
+}
+
+func (s *ledgerService) requireTransaction(ctx context.Context, id int) error {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if transaction == nil {
+		return domain.ErrTransactionNotFound
+	}
+	return nil
+}
+
+func (s *ledgerService) getAttachment(ctx context.Context, transactionID, id int) (*domain.Attachment, error) {
+	if err := s.requireTransaction(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentRepo.Get(ctx, transactionID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if attachment == nil {
+		return nil, domain.ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// releaseBlob удаляет содержимое без ссылок. Ссылки считаются под той же блокировкой хеша,
+// что и при загрузке, уже после фиксации удаления вложения. Ошибка только пишется в лог:
+// вложение уже удалено, а лишний файл не влияет на данные.
+func (s *ledgerService) releaseBlob(ctx context.Context, sum string) {
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.transactor.Lock(ctx, blobLockKey(sum)); err != nil {
+			return fmt.Errorf("failed to lock blob: %w", err)
+		}
+
+		references, err := s.attachmentRepo.References(ctx, sum)
+		if err != nil {
+			return err
+		}
+		if references > 0 {
+			return nil
+		}
+		return s.blobStore.Delete(ctx, sum)
+	})
+	if err != nil {
+		log.Printf("Failed to release blob %s: %v", sum, err)
+	}
+}
+
+func blobLockKey(sum string) string {
+	return "blob:" + sum
+}
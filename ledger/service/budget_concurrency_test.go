@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ledger/domain"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryTransactions хранит транзакции в памяти. Между чтением расходов и возвратом
+// результата выдерживается пауза, чтобы параллельные проверки без блокировок пересекались.
+type memoryTransactions struct {
+	domain.TransactionRepository
+
+	mu           sync.Mutex
+	transactions []domain.Transaction
+	overspends   map[int][]domain.Overspend
+}
+
+func (r *memoryTransactions) Create(ctx context.Context, transaction domain.Transaction, overspends ...domain.Overspend) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction.ID = len(r.transactions) + 1
+	r.transactions = append(r.transactions, transaction)
+	if len(overspends) > 0 {
+		if r.overspends == nil {
+			r.overspends = make(map[int][]domain.Overspend)
+		}
+		r.overspends[transaction.ID] = overspends
+	}
+	return transaction.ID, nil
+}
+
+func (r *memoryTransactions) GetSpendingByCategoryTreeAndPeriod(ctx context.Context, category string, from, to time.Time, currency string) (domain.Money, error) {
+	r.mu.Lock()
+	var total domain.Money
+	for _, transaction := range r.transactions {
+		if transaction.CountsToward(category, from, to) {
+			total += transaction.Amount
+		}
+	}
+	r.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+	return total, nil
+}
+
+func (r *memoryTransactions) total(category string) domain.Money {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total domain.Money
+	for _, transaction := range r.transactions {
+		if transaction.Category == category || strings.HasPrefix(transaction.Category, category+"/") {
+			total += transaction.Amount
+		}
+	}
+	return total
+}
+
+type memoryBudgets struct {
+	domain.BudgetRepository
+	budgets map[string]domain.Budget
+}
+
+func (r *memoryBudgets) GetByCategory(ctx context.Context, category string, date time.Time) (*domain.Budget, error) {
+	budget, ok := r.budgets[category]
+	if !ok {
+		return nil, nil
+	}
+	return &budget, nil
+}
+
+func (r *memoryBudgets) OverridesAt(ctx context.Context, budgetID int, date time.Time) ([]domain.LimitOverride, error) {
+	return nil, nil
+}
+
+type memoryCategories struct {
+	domain.CategoryRepository
+}
+
+func (r *memoryCategories) Ensure(ctx context.Context, name string) error {
+	return nil
+}
+
+type memoryPools struct {
+	domain.BudgetPoolRepository
+}
+
+func (r *memoryPools) ListByCategories(ctx context.Context, categories []string, date time.Time) ([]domain.BudgetPool, error) {
+	return nil, nil
+}
+
+type lockSet struct {
+	held []*sync.Mutex
+}
+
+type lockSetKey struct{}
+
+// memoryTransactor повторяет advisory-блокировки: ключ занят до конца WithinTransaction.
+type memoryTransactor struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func (t *memoryTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	set := &lockSet{}
+	defer func() {
+		for _, lock := range set.held {
+			lock.Unlock()
+		}
+	}()
+
+	return fn(context.WithValue(ctx, lockSetKey{}, set))
+}
+
+func (t *memoryTransactor) Lock(ctx context.Context, keys ...string) error {
+	set, ok := ctx.Value(lockSetKey{}).(*lockSet)
+	if !ok {
+		return errors.New("lock requires a transaction")
+	}
+
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	for i, key := range sorted {
+		if i > 0 && key == sorted[i-1] {
+			continue
+		}
+
+		t.mu.Lock()
+		lock, ok := t.locks[key]
+		if !ok {
+			lock = &sync.Mutex{}
+			t.locks[key] = lock
+		}
+		t.mu.Unlock()
+
+		lock.Lock()
+		set.held = append(set.held, lock)
+	}
+
+	return nil
+}
+
+func newConcurrencyTestService(transactions *memoryTransactions, budgets map[string]domain.Budget) LedgerService {
+	return NewLedgerService(
+		transactions,
+		&memoryBudgets{budgets: budgets},
+		&memoryCategories{},
+		&memoryPools{},
+		nil, nil, nil, nil, nil, nil,
+		&memoryTransactor{locks: make(map[string]*sync.Mutex)},
+	)
+}
+
+func TestCreateTransactionConcurrentBudgetLimit(t *testing.T) {
+	t.Parallel()
+
+	const limit = domain.Money(100000)
+	const amount = domain.Money(10000)
+
+	testCases := []struct {
+		name       string
+		categories []string
+	}{
+		{name: "same category", categories: []string{"Транспорт"}},
+		{name: "sibling subcategories", categories: []string{"Транспорт/Такси", "Транспорт/Метро"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			transactions := &memoryTransactions{}
+			service := newConcurrencyTestService(transactions, map[string]domain.Budget{
+				"Транспорт": {ID: 1, Category: "Транспорт", Limit: limit, Currency: domain.BaseCurrency, Period: domain.PeriodMonthly},
+			})
+
+			const requests = 40
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			accepted := 0
+
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+
+					_, err := service.CreateTransaction(context.Background(), domain.CreateTransactionRequest{
+						Amount:   amount,
+						Category: tc.categories[i%len(tc.categories)],
+					})
+					if err != nil && !errors.Is(err, domain.ErrBudgetExceeded) {
+						t.Errorf("Expected nil or ErrBudgetExceeded, got %v", err)
+						return
+					}
+					if err == nil {
+						mu.Lock()
+						accepted++
+						mu.Unlock()
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			if total := transactions.total("Транспорт"); total > limit {
+				t.Errorf("Expected spending within limit %s, got %s", limit, total)
+			}
+			if want := int(limit / amount); accepted != want {
+				t.Errorf("Expected %d accepted transactions, got %d", want, accepted)
+			}
+		})
+	}
+}
+
+func TestCreateTransactionsBulkRespectsBudgetLimit(t *testing.T) {
+	t.Parallel()
+
+	transactions := &memoryTransactions{}
+	service := newConcurrencyTestService(transactions, map[string]domain.Budget{
+		"Продукты": {ID: 1, Category: "Продукты", Limit: 50000, Currency: domain.BaseCurrency, Period: domain.PeriodMonthly},
+	})
+
+	req := domain.BulkTransactionRequest{}
+	for i := 0; i < 30; i++ {
+		req.Transactions = append(req.Transactions, domain.CreateTransactionRequest{
+			Amount:      3000,
+			Category:    "Продукты",
+			Description: fmt.Sprintf("покупка %d", i),
+		})
+	}
+
+	response, err := service.CreateTransactionsBulk(context.Background(), req, 8)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if total := transactions.total("Продукты"); total > 50000 {
+		t.Errorf("Expected spending within limit 500.00, got %s", total)
+	}
+	if response.Accepted != 16 {
+		t.Errorf("Expected 16 accepted transactions, got %d", response.Accepted)
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"ledger/domain"
+	"math"
+)
+
+// budgetCheck — результат проверки транзакции по бюджетам, если она может быть принята.
+type budgetCheck struct {
+	warnings   []domain.BudgetWarning
+	overspends []domain.Overspend
+	// enforce — отклонять транзакцию по политике бюджета; без него превышения только учитываются.
+	enforce bool
+}
+
+// checkBudgetRule проверяет транзакцию по бюджетам её категории и всех родительских категорий
+// (бюджет родителя покрывает расходы потомков), а также по общим бюджетам, в которые они входят.
+// При превышении лимита действует политика бюджета: block возвращает ErrBudgetExceeded,
+// allow принимает транзакцию с пометкой о превышении, override требует подтверждения с причиной.
+// Общие бюджеты всегда блокируют превышение. adjustments — ещё не сохранённые изменения
+// расходов (например, прежняя версия изменяемой транзакции с обратной суммой).
+// Суммы пересчитываются в валюту бюджета по курсу на дату транзакции,
+// общие бюджеты ведутся в базовой валюте.
+func (s *ledgerService) checkBudgetRule(ctx context.Context, transaction domain.Transaction, adjustments ...domain.Transaction) (*budgetCheck, error) {
+	check := &budgetCheck{enforce: true}
+	if err := s.evaluateBudgets(ctx, transaction, adjustments, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// recheckOverspends заново считает превышения бюджетов для изменённой транзакции, расходы
+// по которой не выросли. Такое изменение не отклоняется, а записи о превышении приводятся
+// в соответствие с новой суммой: если бюджет больше не превышен, запись исчезает.
+func (s *ledgerService) recheckOverspends(ctx context.Context, transaction domain.Transaction, adjustments ...domain.Transaction) ([]domain.Overspend, error) {
+	check := &budgetCheck{}
+	if err := s.evaluateBudgets(ctx, transaction, adjustments, check); err != nil {
+		return nil, err
+	}
+	return check.overspends, nil
+}
+
+// merge добавляет результаты other для бюджетов, которых ещё нет в check.
+func (c *budgetCheck) merge(other *budgetCheck) {
+	checked := make(map[string]bool, len(c.overspends)+len(c.warnings))
+	for _, overspend := range c.overspends {
+		checked[overspend.BudgetCategory] = true
+	}
+	for _, warning := range c.warnings {
+		checked[warning.Category] = true
+	}
+
+	for _, overspend := range other.overspends {
+		if !checked[overspend.BudgetCategory] {
+			c.overspends = append(c.overspends, overspend)
+		}
+	}
+	for _, warning := range other.warnings {
+		if !checked[warning.Category] {
+			c.warnings = append(c.warnings, warning)
+		}
+	}
+}
+
+func (s *ledgerService) evaluateBudgets(ctx context.Context, transaction domain.Transaction, adjustments []domain.Transaction, check *budgetCheck) error {
+	// Доходы в бюджеты не входят, а новый возврат только уменьшает расходы. Возврат с поправками
+	// (изменённый) или с положительной суммой (сторно возврата) может расходы увеличить.
+	if transaction.Type == domain.TransactionIncome {
+		return nil
+	}
+	if transaction.Amount <= 0 && len(adjustments) == 0 {
+		return nil
+	}
+
+	found := false
+
+	lineage := domain.CategoryLineage(transaction.Category)
+	for _, category := range lineage {
+		budget, err := s.budgetRepo.GetByCategory(ctx, category, transaction.Date)
+		if err != nil {
+			return fmt.Errorf("failed to get budget: %w", err)
+		}
+		if budget == nil {
+			continue
+		}
+		found = true
+
+		if err := s.checkBudget(ctx, *budget, transaction, adjustments, check); err != nil {
+			return err
+		}
+	}
+
+	// Общие бюджеты превышения не записывают, поэтому без enforce их проверять незачем
+	if !check.enforce {
+		return nil
+	}
+
+	pools, err := s.poolRepo.ListByCategories(ctx, lineage, transaction.Date)
+	if err != nil {
+		return fmt.Errorf("failed to get budget pools: %w", err)
+	}
+
+	for _, pool := range pools {
+		found = true
+
+		from, to := pool.PeriodRange(transaction.Date)
+
+		spent, err := s.transactionRepo.GetSpendingByCategoriesAndPeriod(ctx, pool.Categories, from, to, domain.BaseCurrency)
+		if err != nil {
+			return fmt.Errorf("failed to get spent amount for pool %s: %w", pool.Name, err)
+		}
+
+		var adjusted domain.Money
+		for _, adjustment := range adjustments {
+			for _, category := range pool.Categories {
+				if adjustment.CountsToward(category, from, to) {
+					amount, err := s.convert(ctx, adjustment, domain.BaseCurrency)
+					if err != nil {
+						return err
+					}
+					adjusted += amount
+					break
+				}
+			}
+		}
+
+		amount, err := s.convert(ctx, transaction, domain.BaseCurrency)
+		if err != nil {
+			return err
+		}
+
+		// Изменение, которое не увеличивает расходы общего бюджета, не отклоняется
+		if after := spent + adjusted + amount; after > pool.Limit && after > spent {
+			return &domain.PoolExceededError{Pool: pool.Name}
+		}
+	}
+
+	// Бюджет обязателен только для расходов
+	if !found && transaction.Type == domain.TransactionExpense {
+		return domain.ErrBudgetNotFound
+	}
+
+	return nil
+}
+
+// lockBudgetScopes блокирует до конца транзакции бюджеты, которые проверяются для transactions:
+// категории с их родителями и общие бюджеты, куда они входят. Транзакции разных категорий
+// одного бюджета ждут друг друга, а не проходят проверку одновременно.
+func (s *ledgerService) lockBudgetScopes(ctx context.Context, transactions ...domain.Transaction) error {
+	var keys []string
+	for _, transaction := range transactions {
+		if transaction.Type == domain.TransactionIncome {
+			continue
+		}
+
+		lineage := domain.CategoryLineage(transaction.Category)
+		for _, category := range lineage {
+			keys = append(keys, "budget:"+category)
+		}
+
+		pools, err := s.poolRepo.ListByCategories(ctx, lineage, transaction.Date)
+		if err != nil {
+			return fmt.Errorf("failed to get budget pools: %w", err)
+		}
+		for _, pool := range pools {
+			keys = append(keys, "pool:"+pool.Name)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := s.transactor.Lock(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to lock budgets: %w", err)
+	}
+	return nil
+}
+
+func (s *ledgerService) checkBudget(ctx context.Context, budget domain.Budget, transaction domain.Transaction, adjustments []domain.Transaction, check *budgetCheck) error {
+	limit, err := s.budgetService.EffectiveLimit(ctx, budget, transaction.Date)
+	if err != nil {
+		return fmt.Errorf("failed to calculate effective limit: %w", err)
+	}
+
+	from, to := budget.PeriodRange(transaction.Date)
+
+	spent, err := s.transactionRepo.GetSpendingByCategoryTreeAndPeriod(ctx, budget.Category, from, to, budget.Currency)
+	if err != nil {
+		return fmt.Errorf("failed to get spent amount: %w", err)
+	}
+
+	var adjusted domain.Money
+	for _, adjustment := range adjustments {
+		if adjustment.CountsToward(budget.Category, from, to) {
+			amount, err := s.convert(ctx, adjustment, budget.Currency)
+			if err != nil {
+				return err
+			}
+			adjusted += amount
+		}
+	}
+
+	amount, err := s.convert(ctx, transaction, budget.Currency)
+	if err != nil {
+		return err
+	}
+
+	// Изменение, которое не увеличивает расходы бюджета, не отклоняется,
+	// а только учитывается как превышение
+	before := spent + adjusted
+	after := before + amount
+	increased := after > spent
+	if after > limit {
+		overspend := domain.Overspend{
+			BudgetCategory: budget.Category,
+			Policy:         budget.OverspendPolicy,
+			Reason:         transaction.OverrideReason,
+			Limit:          limit,
+			Spent:          after,
+		}
+
+		switch {
+		case !check.enforce || !increased || budget.OverspendPolicy == domain.OverspendAllow:
+			if overspend.Reason == "" {
+				overspend.Reason = domain.DefaultOverspendReason
+			}
+		case budget.OverspendPolicy == domain.OverspendOverride:
+			if !transaction.Override {
+				return domain.ErrOverrideRequired
+			}
+		default:
+			return domain.ErrBudgetExceeded
+		}
+
+		check.overspends = append(check.overspends, overspend)
+		return nil
+	}
+
+	for _, threshold := range budget.CrossedThresholds(limit, before, after) {
+		check.warnings = append(check.warnings, domain.BudgetWarning{
+			Category:    budget.Category,
+			Threshold:   threshold,
+			PercentUsed: math.Round(after.Ratio(limit)*10000) / 100,
+		})
+	}
+
+	return nil
+}
+
+// convert переводит сумму транзакции в валюту currency по курсу на дату транзакции.
+func (s *ledgerService) convert(ctx context.Context, transaction domain.Transaction, currency string) (domain.Money, error) {
+	if transaction.Currency == currency {
+		return transaction.Amount, nil
+	}
+
+	rate, err := s.rateRepo.Rate(ctx, transaction.Currency, currency, transaction.Date)
+	if err != nil {
+		return 0, err
+	}
+
+	return transaction.Amount.Convert(rate), nil
+}
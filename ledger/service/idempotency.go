@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"ledger/domain"
+)
+
+func (s *ledgerService) BeginIdempotent(ctx context.Context, req domain.IdempotentRequest) (*domain.StoredResponse, error) {
+	if err := domain.ValidateIdempotencyKey(req.Key); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	record := domain.IdempotencyRecord{
+		Key:         req.Key,
+		Method:      req.Method,
+		Path:        req.Path,
+		Fingerprint: domain.IdempotencyFingerprint(req.Method, req.Path, req.Body),
+	}
+
+	existing, claimed, err := s.idempotencyRepo.Claim(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	if existing.Fingerprint != record.Fingerprint {
+		return nil, domain.ErrIdempotencyKeyMismatch
+	}
+	if existing.Response == nil {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return existing.Response, nil
+}
+
+func (s *ledgerService) CompleteIdempotent(ctx context.Context, key string, response domain.StoredResponse) error {
+	return s.idempotencyRepo.Complete(ctx, key, response)
+}
+
+func (s *ledgerService) ReleaseIdempotent(ctx context.Context, key string) error {
+	return s.idempotencyRepo.Release(ctx, key)
+}
@@ -2,15 +2,58 @@ package service
 
 import (
 	"context"
+	"io"
 	"ledger/domain"
+	"time"
 )
 
 type LedgerService interface {
 	CreateTransaction(ctx context.Context, req domain.CreateTransactionRequest) (*domain.TransactionResponse, error)
-	ListTransactions(ctx context.Context) ([]domain.TransactionResponse, error)
+	GetTransaction(ctx context.Context, id int) (*domain.TransactionResponse, error)
+	ListTransactions(ctx context.Context, req domain.ListTransactionsRequest) (*domain.TransactionPageResponse, error)
+	SearchTransactions(ctx context.Context, req domain.SearchTransactionsRequest) ([]domain.SearchResultResponse, error)
+	UpdateTransaction(ctx context.Context, id int, req domain.UpdateTransactionRequest) (*domain.TransactionResponse, error)
+	DeleteTransaction(ctx context.Context, id int, mode string) (*domain.TransactionResponse, error)
+	CreateSplit(ctx context.Context, req domain.CreateSplitRequest) (*domain.SplitResponse, error)
+	GetSplit(ctx context.Context, id int) (*domain.SplitResponse, error)
+	DeleteSplit(ctx context.Context, id int) error
+	UploadAttachment(ctx context.Context, req domain.UploadAttachmentRequest) (*domain.AttachmentResponse, error)
+	ListAttachments(ctx context.Context, transactionID int) ([]domain.AttachmentResponse, error)
+	// OpenAttachment возвращает вложение и его содержимое; содержимое закрывает вызывающий.
+	OpenAttachment(ctx context.Context, transactionID, id int) (*domain.AttachmentResponse, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, transactionID, id int) error
+	GetTransactionHistory(ctx context.Context, id int) ([]domain.TransactionChangeResponse, error)
 	CreateBudget(ctx context.Context, req domain.CreateBudgetRequest) (*domain.BudgetResponse, error)
-	ListBudgets(ctx context.Context) ([]domain.BudgetResponse, error)
+	ListBudgets(ctx context.Context, req domain.ListBudgetsRequest) ([]domain.BudgetResponse, error)
+	GetBudget(ctx context.Context, category string) (*domain.BudgetResponse, error)
+	GetBudgetStatus(ctx context.Context, category string) (*domain.BudgetStatusResponse, error)
+	GetBudgetSuggestions(ctx context.Context, req domain.GetBudgetSuggestionsRequest) ([]domain.BudgetSuggestionResponse, error)
+	ApplyBudgetSuggestions(ctx context.Context, req domain.GetBudgetSuggestionsRequest) ([]domain.BudgetResponse, error)
+	GetBudgetHistory(ctx context.Context, category string) (*domain.BudgetHistoryResponse, error)
+	CreateLimitOverride(ctx context.Context, req domain.CreateLimitOverrideRequest) (*domain.LimitOverrideResponse, error)
+	CreateBudgetPool(ctx context.Context, req domain.CreateBudgetPoolRequest) (*domain.BudgetPoolResponse, error)
+	ListBudgetPools(ctx context.Context) ([]domain.BudgetPoolResponse, error)
 	HealthCheck(ctx context.Context) error
 	GetSpendingSummary(ctx context.Context, req domain.GetSpendingSummaryRequest) (domain.SpendingSummary, error)
+	GetSpendingBreakdown(ctx context.Context, req domain.GetSpendingSummaryRequest) ([]domain.CategorySpending, error)
+	GetBudgetForecast(ctx context.Context, req domain.GetBudgetForecastRequest) ([]domain.BudgetForecastResponse, error)
+	GetTagSummary(ctx context.Context, req domain.GetTagSummaryRequest) ([]domain.TagSpendingResponse, error)
+	GetCashFlow(ctx context.Context, req domain.GetCashFlowRequest) (*domain.CashFlowResponse, error)
+	GetOverspendReport(ctx context.Context, req domain.GetOverspendReportRequest) ([]domain.OverspendResponse, error)
+	CreateRecurring(ctx context.Context, req domain.CreateRecurringRequest) (*domain.RecurringResponse, error)
+	ListRecurring(ctx context.Context) ([]domain.RecurringResponse, error)
+	DeleteRecurring(ctx context.Context, id int) error
+	ListOccurrences(ctx context.Context, req domain.ListOccurrencesRequest) ([]domain.OccurrenceResponse, error)
+	// PostDueRecurring проводит наступившие к моменту at повторяющиеся транзакции и возвращает число проведённых.
+	PostDueRecurring(ctx context.Context, at time.Time) (int, error)
+	CreateExchangeRate(ctx context.Context, req domain.CreateExchangeRateRequest) (*domain.ExchangeRateResponse, error)
+	ListExchangeRates(ctx context.Context, req domain.ListExchangeRatesRequest) ([]domain.ExchangeRateResponse, error)
+	ListCategories(ctx context.Context) ([]domain.CategoryResponse, error)
+	// BeginIdempotent занимает ключ запроса. Возвращает сохранённый ответ, если запрос
+	// с этим ключом уже выполнен, и nil, если запрос нужно выполнить.
+	BeginIdempotent(ctx context.Context, req domain.IdempotentRequest) (*domain.StoredResponse, error)
+	CompleteIdempotent(ctx context.Context, key string, response domain.StoredResponse) error
+	// ReleaseIdempotent освобождает ключ, если запрос не выполнен и его можно повторить.
+	ReleaseIdempotent(ctx context.Context, key string) error
 	CreateTransactionsBulk(ctx context.Context, req domain.BulkTransactionRequest, workers int) (*domain.BulkTransactionResponse, error)
 }
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"ledger/domain"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,48 +16,434 @@ import (
 type ledgerService struct {
 	transactionRepo domain.TransactionRepository
 	budgetRepo      domain.BudgetRepository
+	categoryRepo    domain.CategoryRepository
+	poolRepo        domain.BudgetPoolRepository
+	overspendRepo   domain.OverspendRepository
+	rateRepo        domain.ExchangeRateRepository
+	recurringRepo   domain.RecurringRepository
+	attachmentRepo  domain.AttachmentRepository
+	blobStore       domain.BlobStore
+	idempotencyRepo domain.IdempotencyRepository
+	transactor      domain.Transactor
+	budgetService   *domain.BudgetService
 }
 
 func NewLedgerService(
 	transactionRepo domain.TransactionRepository,
 	budgetRepo domain.BudgetRepository,
+	categoryRepo domain.CategoryRepository,
+	poolRepo domain.BudgetPoolRepository,
+	overspendRepo domain.OverspendRepository,
+	rateRepo domain.ExchangeRateRepository,
+	recurringRepo domain.RecurringRepository,
+	attachmentRepo domain.AttachmentRepository,
+	blobStore domain.BlobStore,
+	idempotencyRepo domain.IdempotencyRepository,
+	transactor domain.Transactor,
 ) LedgerService {
 	return &ledgerService{
 		transactionRepo: transactionRepo,
 		budgetRepo:      budgetRepo,
+		categoryRepo:    categoryRepo,
+		poolRepo:        poolRepo,
+		overspendRepo:   overspendRepo,
+		rateRepo:        rateRepo,
+		recurringRepo:   recurringRepo,
+		attachmentRepo:  attachmentRepo,
+		blobStore:       blobStore,
+		idempotencyRepo: idempotencyRepo,
+		transactor:      transactor,
+		budgetService:   domain.NewBudgetService(budgetRepo, transactionRepo),
 	}
 }
 
 func (s *ledgerService) CreateTransaction(ctx context.Context, req domain.CreateTransactionRequest) (*domain.TransactionResponse, error) {
 	if err := s.validateTransactionRequest(req); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
 	}
 
 	transaction := req.ToEntity()
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+
+	// Проверка и запись идут в одной транзакции под блокировкой бюджетов,
+	// иначе параллельные запросы могут вместе превысить лимит
+	var check *budgetCheck
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.lockBudgetScopes(ctx, transaction); err != nil {
+			return err
+		}
+
+		var err error
+		check, err = s.checkBudgetRule(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		// Категория появляется, только если транзакция принята
+		if err := s.categoryRepo.Ensure(ctx, transaction.Category); err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
 
-	if err := s.checkBudgetRule(ctx, transaction.Category, transaction.Amount); err != nil {
+		id, err := s.transactionRepo.Create(ctx, transaction, check.overspends...)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+		transaction.ID = id
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	id, err := s.transactionRepo.Create(ctx, transaction)
+	transaction.OverBudget = len(check.overspends) > 0
+	response := domain.TransactionResponseFromEntity(transaction)
+	response.Warnings = check.warnings
+	return &response, nil
+}
+
+// GetTransaction возвращает транзакцию; удалённые считаются несуществующими.
+func (s *ledgerService) GetTransaction(ctx context.Context, id int) (*domain.TransactionResponse, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if transaction == nil {
+		return nil, domain.ErrTransactionNotFound
 	}
 
-	transaction.ID = id
-	response := domain.TransactionResponseFromEntity(transaction)
+	response := domain.TransactionResponseFromEntity(*transaction)
 	return &response, nil
 }
 
-func (s *ledgerService) ListTransactions(ctx context.Context) ([]domain.TransactionResponse, error) {
-	transactions, err := s.transactionRepo.List(ctx)
+// ListTransactions отдаёт страницу транзакций. Следующая страница запрашивается
+// с курсором последней транзакции, поэтому новые записи не сдвигают выдачу.
+func (s *ledgerService) ListTransactions(ctx context.Context, req domain.ListTransactionsRequest) (*domain.TransactionPageResponse, error) {
+	if err := domain.ValidateTags(req.Tags); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	filter, err := req.ToFilter()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+
+	transactions, err := s.transactionRepo.List(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list transactions: %w", err)
 	}
 
-	responses := make([]domain.TransactionResponse, len(transactions))
+	page := &domain.TransactionPageResponse{}
+	if len(transactions) > pageSize {
+		transactions = transactions[:pageSize]
+		page.NextCursor = domain.CursorAfter(filter.Sort, transactions[pageSize-1]).Encode()
+	}
+
+	page.Transactions = make([]domain.TransactionResponse, len(transactions))
 	for i, transaction := range transactions {
-		responses[i] = domain.TransactionResponseFromEntity(transaction)
+		page.Transactions[i] = domain.TransactionResponseFromEntity(transaction)
+	}
+
+	return page, nil
+}
+
+func (s *ledgerService) SearchTransactions(ctx context.Context, req domain.SearchTransactionsRequest) ([]domain.SearchResultResponse, error) {
+	search := req.ToSearch()
+	if err := search.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	results, err := s.transactionRepo.Search(ctx, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+
+	responses := make([]domain.SearchResultResponse, len(results))
+	for i, result := range results {
+		responses[i] = domain.SearchResultResponseFromEntity(result)
+	}
+
+	return responses, nil
+}
+
+// UpdateTransaction меняет транзакцию. Если выросла сумма (в том числе уменьшился возврат) или
+// сменились тип, валюта, категория или дата, бюджеты проверяются заново так, будто прежней версии
+// транзакции не было; прежний возврат, перенесённый из категории, проверяется по её бюджетам.
+// Если сумма только уменьшилась, изменение не отклоняется, но записи о превышении пересчитываются.
+func (s *ledgerService) UpdateTransaction(ctx context.Context, id int, req domain.UpdateTransactionRequest) (*domain.TransactionResponse, error) {
+	var transaction domain.Transaction
+	var overspends []domain.Overspend
+	var warnings []domain.BudgetWarning
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		overspends, warnings = nil, nil
+
+		// Строка блокируется до конца транзакции, чтобы прежняя версия не устарела
+		// из-за параллельного изменения
+		current, err := s.transactionRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if current == nil {
+			return domain.ErrTransactionNotFound
+		}
+		if current.Locked() {
+			return domain.ErrTransactionReversed
+		}
+		if current.SplitID != nil {
+			return domain.ErrSplitLine
+		}
+
+		transaction = req.Apply(*current)
+
+		amount := current.Amount.Abs()
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+
+		if err := s.validateTransactionRequest(domain.CreateTransactionRequest{
+			Type:           transaction.Type,
+			Amount:         amount,
+			Currency:       transaction.Currency,
+			Category:       transaction.Category,
+			Tags:           transaction.Tags,
+			Override:       transaction.Override,
+			OverrideReason: transaction.OverrideReason,
+		}); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrValidation, err)
+		}
+
+		reshaped := transaction.Type != current.Type || transaction.Currency != current.Currency ||
+			transaction.Category != current.Category || !transaction.Date.Equal(current.Date)
+		rechecked := reshaped || transaction.Amount != current.Amount
+
+		if rechecked {
+			previous := *current
+			previous.Amount = -current.Amount
+
+			if err := s.lockBudgetScopes(ctx, transaction, *current); err != nil {
+				return err
+			}
+
+			if reshaped || transaction.Amount > current.Amount {
+				check, err := s.checkBudgetRule(ctx, transaction, previous)
+				if err != nil {
+					return err
+				}
+
+				// Без прежнего возврата расходы его бюджетов растут
+				if reshaped && current.Type == domain.TransactionRefund {
+					removed := previous
+					removed.Override, removed.OverrideReason = transaction.Override, transaction.OverrideReason
+
+					removal, err := s.checkBudgetRule(ctx, removed, transaction)
+					if err != nil {
+						return err
+					}
+					check.merge(removal)
+				}
+				overspends, warnings = check.overspends, check.warnings
+			} else {
+				overspends, err = s.recheckOverspends(ctx, transaction, previous)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := s.categoryRepo.Ensure(ctx, transaction.Category); err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+
+		if err := s.transactionRepo.Update(ctx, transaction); err != nil {
+			return err
+		}
+
+		if rechecked {
+			if err := s.overspendRepo.Replace(ctx, id, overspends); err != nil {
+				return fmt.Errorf("failed to record overspend: %w", err)
+			}
+			transaction.OverBudget = len(overspends) > 0
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := domain.TransactionResponseFromEntity(transaction)
+	response.Warnings = warnings
+	return &response, nil
+}
+
+// DeleteTransaction удаляет транзакцию мягко или, в режиме reversal, добавляет сторнирующую
+// запись и возвращает её. В обоих случаях прежние значения остаются в журнале изменений.
+// Удаление и сторно возврата увеличивают расходы категории и проверяются по её бюджетам;
+// превышение записывается на сторнирующую запись.
+func (s *ledgerService) DeleteTransaction(ctx context.Context, id int, mode string) (*domain.TransactionResponse, error) {
+	if mode != "" && mode != domain.DeleteModeSoft && mode != domain.DeleteModeReversal {
+		return nil, fmt.Errorf("%w: mode must be 'soft' or 'reversal'", domain.ErrValidation)
+	}
+
+	var response *domain.TransactionResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		response = nil
+
+		// Строка блокируется до конца транзакции, чтобы параллельные удаление и сторно
+		// не прошли проверки одновременно
+		current, err := s.transactionRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if current == nil {
+			return domain.ErrTransactionNotFound
+		}
+		if current.Locked() {
+			return domain.ErrTransactionReversed
+		}
+		if current.SplitID != nil {
+			return domain.ErrSplitLine
+		}
+
+		reversal := current.Reversal()
+
+		check := &budgetCheck{}
+		if current.Type == domain.TransactionRefund {
+			if err := s.lockBudgetScopes(ctx, reversal); err != nil {
+				return err
+			}
+			if check, err = s.checkBudgetRule(ctx, reversal); err != nil {
+				return err
+			}
+		}
+
+		if mode != domain.DeleteModeReversal {
+			return s.transactionRepo.Delete(ctx, id)
+		}
+
+		reversal.ID, err = s.transactionRepo.Reverse(ctx, reversal)
+		if err != nil {
+			return err
+		}
+
+		if len(check.overspends) > 0 {
+			if err := s.overspendRepo.Record(ctx, reversal.ID, check.overspends); err != nil {
+				return fmt.Errorf("failed to record overspend: %w", err)
+			}
+			reversal.OverBudget = true
+		}
+
+		reversalResponse := domain.TransactionResponseFromEntity(reversal)
+		reversalResponse.Warnings = check.warnings
+		response = &reversalResponse
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// CreateSplit разносит платёж по категориям. Каждая строка проверяется по бюджетам своей
+// категории с учётом уже проверенных строк, и платёж принимается, только если приняты все строки.
+func (s *ledgerService) CreateSplit(ctx context.Context, req domain.CreateSplitRequest) (*domain.SplitResponse, error) {
+	split := req.ToEntity()
+	if err := split.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+	if split.Date.IsZero() {
+		split.Date = time.Now()
+	}
+
+	for i := range split.Lines {
+		split.Lines[i].Date = split.Date
+	}
+
+	var warnings []domain.BudgetWarning
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Блокировки всех строк берутся сразу, чтобы два платежа не ждали друг друга по кругу
+		if err := s.lockBudgetScopes(ctx, split.Lines...); err != nil {
+			return err
+		}
+
+		checks := make([]*budgetCheck, len(split.Lines))
+		for i, line := range split.Lines {
+			check, err := s.checkBudgetRule(ctx, line, split.Lines[:i]...)
+			if err != nil {
+				return err
+			}
+			checks[i] = check
+		}
+
+		for _, line := range split.Lines {
+			if err := s.categoryRepo.Ensure(ctx, line.Category); err != nil {
+				return fmt.Errorf("failed to create category: %w", err)
+			}
+		}
+
+		if err := s.transactionRepo.CreateSplit(ctx, &split); err != nil {
+			return fmt.Errorf("failed to create split: %w", err)
+		}
+
+		warnings = nil
+		for i, check := range checks {
+			if len(check.overspends) > 0 {
+				if err := s.overspendRepo.Record(ctx, split.Lines[i].ID, check.overspends); err != nil {
+					return fmt.Errorf("failed to record overspend: %w", err)
+				}
+			}
+			split.Lines[i].OverBudget = len(check.overspends) > 0
+			warnings = append(warnings, check.warnings...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := domain.SplitResponseFromEntity(split)
+	response.Warnings = warnings
+	return &response, nil
+}
+
+func (s *ledgerService) GetSplit(ctx context.Context, id int) (*domain.SplitResponse, error) {
+	split, err := s.transactionRepo.GetSplit(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get split: %w", err)
+	}
+	if split == nil {
+		return nil, domain.ErrSplitNotFound
+	}
+
+	response := domain.SplitResponseFromEntity(*split)
+	return &response, nil
+}
+
+// DeleteSplit мягко удаляет платёж целиком; строки по отдельности не удаляются.
+func (s *ledgerService) DeleteSplit(ctx context.Context, id int) error {
+	return s.transactionRepo.DeleteSplit(ctx, id)
+}
+
+func (s *ledgerService) GetTransactionHistory(ctx context.Context, id int) ([]domain.TransactionChangeResponse, error) {
+	changes, err := s.transactionRepo.Changes(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction history: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil, domain.ErrTransactionNotFound
+	}
+
+	responses := make([]domain.TransactionChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = domain.TransactionChangeResponseFromEntity(change)
 	}
 
 	return responses, nil
@@ -62,39 +451,346 @@ func (s *ledgerService) ListTransactions(ctx context.Context) ([]domain.Transact
 
 func (s *ledgerService) CreateBudget(ctx context.Context, req domain.CreateBudgetRequest) (*domain.BudgetResponse, error) {
 	if err := s.validateBudgetRequest(req); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
 	}
 
 	budget := req.ToEntity()
 
-	if err := s.budgetRepo.Save(ctx, budget); err != nil {
+	if err := s.categoryRepo.Ensure(ctx, budget.Category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	if err := s.budgetRepo.Save(ctx, &budget); err != nil {
+		if errors.Is(err, domain.ErrBudgetCurrencyChanged) {
+			return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+		}
 		return nil, fmt.Errorf("failed to create budget: %w", err)
 	}
 
-	response := domain.BudgetResponseFromEntity(budget)
-	return &response, nil
+	// Новый лимит может вступать в силу позже, в ответе — лимит, действующий сегодня
+	now := time.Now()
+	limit, err := s.budgetRepo.LimitAt(ctx, budget.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget limit: %w", err)
+	}
+	budget.Limit = limit
+
+	return s.budgetResponse(ctx, budget, now)
 }
 
-func (s *ledgerService) ListBudgets(ctx context.Context) ([]domain.BudgetResponse, error) {
+func (s *ledgerService) ListBudgets(ctx context.Context, req domain.ListBudgetsRequest) ([]domain.BudgetResponse, error) {
 	budgets, err := s.budgetRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list budgets: %w", err)
 	}
 
+	now := time.Now()
 	responses := make([]domain.BudgetResponse, len(budgets))
 	for i, budget := range budgets {
-		responses[i] = domain.BudgetResponseFromEntity(budget)
+		response, err := s.budgetResponse(ctx, budget, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.WithStatus {
+			status, err := s.budgetService.StatusOf(ctx, budget, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get budget status: %w", err)
+			}
+			statusResponse := domain.BudgetStatusResponseFromEntity(*status)
+			response.Status = &statusResponse
+		}
+
+		responses[i] = *response
 	}
 
 	return responses, nil
 }
 
+func (s *ledgerService) GetBudget(ctx context.Context, category string) (*domain.BudgetResponse, error) {
+	now := time.Now()
+
+	budget, err := s.budgetRepo.GetByCategory(ctx, category, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if budget == nil {
+		return nil, domain.ErrBudgetNotFound
+	}
+
+	return s.budgetResponse(ctx, *budget, now)
+}
+
+func (s *ledgerService) GetBudgetStatus(ctx context.Context, category string) (*domain.BudgetStatusResponse, error) {
+	status, err := s.budgetService.GetBudgetStatus(ctx, category, time.Now())
+	if errors.Is(err, domain.ErrBudgetNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget status: %w", err)
+	}
+
+	response := domain.BudgetStatusResponseFromEntity(*status)
+	return &response, nil
+}
+
+// GetBudgetSuggestions предлагает лимиты по истории расходов: перцентиль (по умолчанию медиана)
+// последних завершённых периодов без выбросов. Категории без расходов пропускаются.
+func (s *ledgerService) GetBudgetSuggestions(ctx context.Context, req domain.GetBudgetSuggestionsRequest) ([]domain.BudgetSuggestionResponse, error) {
+	suggestions, err := s.suggestBudgets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]domain.BudgetSuggestionResponse, len(suggestions))
+	for i, suggestion := range suggestions {
+		responses[i] = domain.BudgetSuggestionResponseFromEntity(suggestion)
+	}
+
+	return responses, nil
+}
+
+// ApplyBudgetSuggestions создаёт бюджеты по предложениям для выбранных категорий.
+// Если хотя бы для одной категории предложения нет, ни один бюджет не создаётся.
+func (s *ledgerService) ApplyBudgetSuggestions(ctx context.Context, req domain.GetBudgetSuggestionsRequest) ([]domain.BudgetResponse, error) {
+	if len(req.Categories) == 0 {
+		return nil, fmt.Errorf("%w: at least one category is required", domain.ErrValidation)
+	}
+
+	suggestions, err := s.suggestBudgets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]domain.BudgetSuggestion, len(suggestions))
+	for _, suggestion := range suggestions {
+		byCategory[suggestion.Category] = suggestion
+	}
+
+	for _, category := range req.Categories {
+		if _, ok := byCategory[category]; !ok {
+			return nil, fmt.Errorf("%w: no spending history to suggest a budget for %s", domain.ErrValidation, category)
+		}
+	}
+
+	// Бюджеты применяются все вместе: при ошибке ни один из них не сохраняется
+	var responses []domain.BudgetResponse
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		responses = make([]domain.BudgetResponse, 0, len(req.Categories))
+		for _, category := range req.Categories {
+			suggestion := byCategory[category]
+
+			response, err := s.CreateBudget(ctx, domain.CreateBudgetRequest{
+				Category: suggestion.Category,
+				Limit:    suggestion.Limit,
+				Period:   suggestion.Period,
+			})
+			if err != nil {
+				return err
+			}
+			responses = append(responses, *response)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+func (s *ledgerService) suggestBudgets(ctx context.Context, req domain.GetBudgetSuggestionsRequest) ([]domain.BudgetSuggestion, error) {
+	if req.Period == "" {
+		req.Period = domain.PeriodMonthly
+	}
+	if req.Periods == 0 {
+		req.Periods = domain.DefaultSuggestionPeriods
+	}
+	if req.Percentile == 0 {
+		req.Percentile = domain.DefaultSuggestionPercentile
+	}
+	if req.Date.IsZero() {
+		req.Date = time.Now()
+	}
+
+	if err := validateSuggestionRequest(req); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	categories := req.Categories
+	if len(categories) == 0 {
+		all, err := s.categoryRepo.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list categories: %w", err)
+		}
+		for _, category := range all {
+			categories = append(categories, category.Name)
+		}
+	}
+
+	starts := domain.PreviousPeriodStarts(req.Period, req.Date, req.Periods)
+	current, _ := domain.PeriodRange(req.Period, req.Date)
+
+	spendings, err := s.transactionRepo.GetSpendingByPeriods(ctx, categories, req.Period, starts[0], current.Add(-time.Second), domain.BaseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending history: %w", err)
+	}
+
+	byCategory := make(map[string]map[string]domain.Money)
+	for _, spending := range spendings {
+		if byCategory[spending.Category] == nil {
+			byCategory[spending.Category] = make(map[string]domain.Money)
+		}
+		byCategory[spending.Category][spending.PeriodStart.Format("2006-01-02")] += spending.Amount
+	}
+
+	names := make([]string, 0, len(byCategory))
+	for name := range byCategory {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suggestions := make([]domain.BudgetSuggestion, 0, len(names))
+	for _, name := range names {
+		// Периоды без расходов тоже входят в основание с нулевой суммой
+		amounts := make([]domain.PeriodAmount, len(starts))
+		for i, start := range starts {
+			amounts[i] = domain.PeriodAmount{
+				PeriodStart: start,
+				Amount:      byCategory[name][start.Format("2006-01-02")],
+			}
+		}
+
+		suggestion := domain.NewBudgetSuggestion(name, req.Period, amounts, req.Percentile)
+		if suggestion.Limit <= 0 {
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+func validateSuggestionRequest(req domain.GetBudgetSuggestionsRequest) error {
+	if req.Period != domain.PeriodDaily && req.Period != domain.PeriodWeekly && req.Period != domain.PeriodMonthly {
+		return fmt.Errorf("period must be 'daily', 'weekly' or 'monthly'")
+	}
+	if req.Periods < 1 || req.Periods > domain.MaxSuggestionPeriods {
+		return fmt.Errorf("periods must be between 1 and %d", domain.MaxSuggestionPeriods)
+	}
+	if req.Percentile <= 0 || req.Percentile > 100 {
+		return fmt.Errorf("percentile must be between 0 and 100")
+	}
+	return nil
+}
+
+func (s *ledgerService) GetBudgetHistory(ctx context.Context, category string) (*domain.BudgetHistoryResponse, error) {
+	versions, err := s.budgetRepo.History(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget history: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, domain.ErrBudgetNotFound
+	}
+
+	overrides, err := s.budgetRepo.Overrides(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limit overrides: %w", err)
+	}
+
+	response := &domain.BudgetHistoryResponse{
+		Versions:  make([]domain.BudgetVersionResponse, len(versions)),
+		Overrides: make([]domain.LimitOverrideResponse, len(overrides)),
+	}
+	for i, version := range versions {
+		response.Versions[i] = domain.BudgetVersionResponseFromEntity(version)
+	}
+
+	now := time.Now()
+	for i, override := range overrides {
+		response.Overrides[i] = domain.LimitOverrideResponseFromEntity(override, now)
+	}
+
+	return response, nil
+}
+
+// CreateLimitOverride временно меняет лимит бюджета категории, действующего на дату начала изменения.
+func (s *ledgerService) CreateLimitOverride(ctx context.Context, req domain.CreateLimitOverrideRequest) (*domain.LimitOverrideResponse, error) {
+	override := req.ToEntity()
+	if err := override.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	budget, err := s.budgetRepo.GetByCategory(ctx, override.Category, override.StartsOn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if budget == nil {
+		return nil, domain.ErrBudgetNotFound
+	}
+
+	override.BudgetID = budget.ID
+	override.Period = budget.Period
+
+	if err := s.budgetRepo.SaveOverride(ctx, &override); err != nil {
+		return nil, fmt.Errorf("failed to create limit override: %w", err)
+	}
+
+	response := domain.LimitOverrideResponseFromEntity(override, time.Now())
+	return &response, nil
+}
+
+func (s *ledgerService) CreateBudgetPool(ctx context.Context, req domain.CreateBudgetPoolRequest) (*domain.BudgetPoolResponse, error) {
+	pool := req.ToEntity()
+	if err := pool.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	for _, category := range pool.Categories {
+		if err := s.categoryRepo.Ensure(ctx, category); err != nil {
+			return nil, fmt.Errorf("failed to create category: %w", err)
+		}
+	}
+
+	if err := s.poolRepo.Save(ctx, &pool); err != nil {
+		return nil, fmt.Errorf("failed to create budget pool: %w", err)
+	}
+
+	response := domain.BudgetPoolResponseFromEntity(pool)
+	return &response, nil
+}
+
+func (s *ledgerService) ListBudgetPools(ctx context.Context) ([]domain.BudgetPoolResponse, error) {
+	pools, err := s.poolRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budget pools: %w", err)
+	}
+
+	responses := make([]domain.BudgetPoolResponse, len(pools))
+	for i, pool := range pools {
+		responses[i] = domain.BudgetPoolResponseFromEntity(pool)
+	}
+
+	return responses, nil
+}
+
+func (s *ledgerService) budgetResponse(ctx context.Context, budget domain.Budget, at time.Time) (*domain.BudgetResponse, error) {
+	limit, err := s.budgetService.EffectiveLimit(ctx, budget, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate effective limit: %w", err)
+	}
+
+	response := domain.BudgetResponseFromEntity(budget)
+	response.EffectiveLimit = limit
+	return &response, nil
+}
+
 func (s *ledgerService) HealthCheck(ctx context.Context) error {
 	if _, err := s.budgetRepo.List(ctx); err != nil {
 		return fmt.Errorf("budget repository unavailable: %w", err)
 	}
 
-	if _, err := s.transactionRepo.List(ctx); err != nil {
+	if _, err := s.transactionRepo.List(ctx, domain.TransactionFilter{Sort: domain.SortDateDesc, Limit: 1}); err != nil {
 		return fmt.Errorf("transaction repository unavailable: %w", err)
 	}
 
@@ -103,12 +799,27 @@ func (s *ledgerService) HealthCheck(ctx context.Context) error {
 
 // Валидации
 func (s *ledgerService) validateTransactionRequest(req domain.CreateTransactionRequest) error {
+	if err := domain.ValidateTransactionType(req.Type); err != nil {
+		return err
+	}
 	if req.Amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
+	if err := domain.ValidateCurrency(req.Currency); err != nil {
+		return err
+	}
+	if err := domain.ValidateTags(req.Tags); err != nil {
+		return err
+	}
 	if req.Category == "" {
 		return fmt.Errorf("category is required")
 	}
+	if err := domain.ValidateCategory(req.Category); err != nil {
+		return err
+	}
+	if req.Override && strings.TrimSpace(req.OverrideReason) == "" {
+		return fmt.Errorf("override reason is required")
+	}
 	return nil
 }
 
@@ -116,31 +827,29 @@ func (s *ledgerService) validateBudgetRequest(req domain.CreateBudgetRequest) er
 	if req.Category == "" {
 		return fmt.Errorf("category is required")
 	}
+	if err := domain.ValidateCategory(req.Category); err != nil {
+		return err
+	}
 	if req.Limit <= 0 {
 		return fmt.Errorf("limit must be positive")
 	}
-	return nil
-}
-
-func (s *ledgerService) checkBudgetRule(ctx context.Context, category string, amount float64) error {
-	budget, err := s.budgetRepo.GetByCategory(ctx, category)
-	if err != nil {
-		return fmt.Errorf("failed to get budget: %w", err)
+	if err := domain.ValidateCurrency(req.Currency); err != nil {
+		return err
 	}
-
-	if budget == nil {
-		return domain.ErrBudgetNotFound
+	if err := domain.ValidatePeriod(req.Period); err != nil {
+		return fmt.Errorf("invalid period: %w", err)
 	}
-
-	spent, err := s.transactionRepo.GetTotalByCategory(ctx, category)
-	if err != nil {
-		return fmt.Errorf("failed to get spent amount: %w", err)
+	if req.Rollover && !domain.IsRecurringPeriod(req.Period) {
+		return fmt.Errorf("rollover requires a recurring period")
 	}
-
-	if spent+amount > budget.Limit {
-		return domain.ErrBudgetExceeded
+	for _, threshold := range req.WarningThresholds {
+		if threshold <= 0 || threshold >= 100 {
+			return fmt.Errorf("warning thresholds must be between 0 and 100")
+		}
+	}
+	if err := domain.ValidateOverspendPolicy(req.OverspendPolicy); err != nil {
+		return err
 	}
-
 	return nil
 }
 
@@ -157,6 +866,14 @@ func (s *ledgerService) GetSpendingSummary(ctx context.Context, req domain.GetSp
 		return nil, fmt.Errorf("period cannot exceed 1 year")
 	}
 
+	if err := domain.ValidateCurrency(req.Currency); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateTags(req.Tags); err != nil {
+		return nil, err
+	}
+
 	categories, err := s.getCategories(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
@@ -166,7 +883,7 @@ func (s *ledgerService) GetSpendingSummary(ctx context.Context, req domain.GetSp
 		return make(domain.SpendingSummary), nil
 	}
 
-	summary, err := s.calculateSpendingParallel(ctx, categories, req.From, req.To)
+	summary, err := s.calculateSpendingParallel(ctx, categories, req.From, req.To, domain.NormalizeCurrency(req.Currency), domain.NormalizeTags(req.Tags))
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate spending: %w", err)
 	}
@@ -174,10 +891,189 @@ func (s *ledgerService) GetSpendingSummary(ctx context.Context, req domain.GetSp
 	return summary, nil
 }
 
+// GetSpendingBreakdown возвращает расходы по дереву категорий с подытогами на каждом уровне:
+// вложенным деревом (view=tree) или плоским списком (view=flat).
+func (s *ledgerService) GetSpendingBreakdown(ctx context.Context, req domain.GetSpendingSummaryRequest) ([]domain.CategorySpending, error) {
+	if req.View != domain.SpendingViewTree && req.View != domain.SpendingViewFlat {
+		return nil, fmt.Errorf("view must be %s or %s", domain.SpendingViewTree, domain.SpendingViewFlat)
+	}
+
+	summary, err := s.GetSpendingSummary(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := domain.BuildSpendingTree(summary)
+	if req.View == domain.SpendingViewFlat {
+		return domain.FlattenSpendingTree(tree), nil
+	}
+	return tree, nil
+}
+
+// GetBudgetForecast прогнозирует по каждой категории с бюджетом, действующим на дату запроса,
+// итог периода и дату исчерпания лимита при текущей скорости расходов.
+func (s *ledgerService) GetBudgetForecast(ctx context.Context, req domain.GetBudgetForecastRequest) ([]domain.BudgetForecastResponse, error) {
+	at := req.Date
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	budgets, err := s.budgetRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	responses := make([]domain.BudgetForecastResponse, 0, len(budgets))
+
+	for _, budget := range budgets {
+		if seen[budget.Category] {
+			continue
+		}
+		seen[budget.Category] = true
+
+		status, err := s.budgetService.GetBudgetStatus(ctx, budget.Category, at)
+		if errors.Is(err, domain.ErrBudgetNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get budget status for %s: %w", budget.Category, err)
+		}
+
+		responses = append(responses, domain.BudgetForecastResponseFromEntity(domain.NewBudgetForecast(*status, at)))
+	}
+
+	return responses, nil
+}
+
+// GetOverspendReport перечисляет транзакции, принятые сверх лимита, с причиной принятия.
+func (s *ledgerService) GetOverspendReport(ctx context.Context, req domain.GetOverspendReportRequest) ([]domain.OverspendResponse, error) {
+	if req.From.IsZero() || req.To.IsZero() {
+		return nil, fmt.Errorf("both from and to dates are required")
+	}
+
+	if req.From.After(req.To) {
+		return nil, fmt.Errorf("from date cannot be after to date")
+	}
+
+	records, err := s.overspendRepo.List(ctx, req.From, req.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overspends: %w", err)
+	}
+
+	responses := make([]domain.OverspendResponse, len(records))
+	for i, record := range records {
+		responses[i] = domain.OverspendResponseFromEntity(record)
+	}
+
+	return responses, nil
+}
+
+// GetCashFlow показывает доходы, расходы и возвраты за период и чистый денежный поток.
+func (s *ledgerService) GetCashFlow(ctx context.Context, req domain.GetCashFlowRequest) (*domain.CashFlowResponse, error) {
+	if req.From.IsZero() || req.To.IsZero() {
+		return nil, fmt.Errorf("both from and to dates are required")
+	}
+
+	if req.From.After(req.To) {
+		return nil, fmt.Errorf("from date cannot be after to date")
+	}
+
+	if err := domain.ValidateCurrency(req.Currency); err != nil {
+		return nil, err
+	}
+
+	cashFlow, err := s.transactionRepo.GetCashFlow(ctx, req.From, req.To, domain.NormalizeCurrency(req.Currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cash flow: %w", err)
+	}
+
+	response := domain.CashFlowResponseFromEntity(cashFlow)
+	return &response, nil
+}
+
+// GetTagSummary считает расходы по меткам за период; транзакция с несколькими метками
+// учитывается в каждой из них, поэтому суммы по меткам не складываются в общий расход.
+func (s *ledgerService) GetTagSummary(ctx context.Context, req domain.GetTagSummaryRequest) ([]domain.TagSpendingResponse, error) {
+	if req.From.IsZero() || req.To.IsZero() {
+		return nil, fmt.Errorf("both from and to dates are required")
+	}
+
+	if req.From.After(req.To) {
+		return nil, fmt.Errorf("from date cannot be after to date")
+	}
+
+	if err := domain.ValidateCurrency(req.Currency); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateTags(req.Tags); err != nil {
+		return nil, err
+	}
+
+	spendings, err := s.transactionRepo.GetSpendingByTags(ctx, req.From, req.To, domain.NormalizeCurrency(req.Currency), domain.NormalizeTags(req.Tags))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending by tags: %w", err)
+	}
+
+	responses := make([]domain.TagSpendingResponse, len(spendings))
+	for i, spending := range spendings {
+		responses[i] = domain.TagSpendingResponseFromEntity(spending)
+	}
+
+	return responses, nil
+}
+
+// CreateExchangeRate сохраняет курс валюты на дату, заменяя прежний курс той же пары на ту же дату.
+func (s *ledgerService) CreateExchangeRate(ctx context.Context, req domain.CreateExchangeRateRequest) (*domain.ExchangeRateResponse, error) {
+	rate := req.ToEntity()
+	if err := rate.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	if err := s.rateRepo.Save(ctx, rate); err != nil {
+		return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	response := domain.ExchangeRateResponseFromEntity(rate)
+	return &response, nil
+}
+
+func (s *ledgerService) ListExchangeRates(ctx context.Context, req domain.ListExchangeRatesRequest) ([]domain.ExchangeRateResponse, error) {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	baseCurrency := strings.ToUpper(strings.TrimSpace(req.BaseCurrency))
+
+	rates, err := s.rateRepo.List(ctx, currency, baseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+
+	responses := make([]domain.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = domain.ExchangeRateResponseFromEntity(rate)
+	}
+
+	return responses, nil
+}
+
+func (s *ledgerService) ListCategories(ctx context.Context) ([]domain.CategoryResponse, error) {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	responses := make([]domain.CategoryResponse, len(categories))
+	for i, category := range categories {
+		responses[i] = domain.CategoryResponseFromEntity(category)
+	}
+
+	return responses, nil
+}
+
 func (s *ledgerService) getCategories(ctx context.Context) ([]string, error) {
-	transactions, err := s.transactionRepo.List(ctx)
+	spent, err := s.transactionRepo.Categories(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions: %w", err)
+		return nil, fmt.Errorf("failed to get transaction categories: %w", err)
 	}
 
 	budgets, err := s.budgetRepo.List(ctx)
@@ -187,9 +1083,9 @@ func (s *ledgerService) getCategories(ctx context.Context) ([]string, error) {
 
 	categorySet := make(map[string]bool)
 
-	for _, tx := range transactions {
-		if tx.Category != "" {
-			categorySet[tx.Category] = true
+	for _, category := range spent {
+		if category != "" {
+			categorySet[category] = true
 		}
 	}
 
@@ -207,11 +1103,11 @@ func (s *ledgerService) getCategories(ctx context.Context) ([]string, error) {
 	return categories, nil
 }
 
-func (s *ledgerService) calculateSpendingParallel(ctx context.Context, categories []string, from, to time.Time) (domain.SpendingSummary, error) {
+func (s *ledgerService) calculateSpendingParallel(ctx context.Context, categories []string, from, to time.Time, currency string, tags []string) (domain.SpendingSummary, error) {
 	var wg sync.WaitGroup
 	results := make(chan struct {
 		category string
-		amount   float64
+		amount   domain.Money
 		err      error
 	}, len(categories))
 
@@ -231,16 +1127,16 @@ func (s *ledgerService) calculateSpendingParallel(ctx context.Context, categorie
 			if ctx.Err() != nil {
 				results <- struct {
 					category string
-					amount   float64
+					amount   domain.Money
 					err      error
 				}{cat, 0, ctx.Err()}
 				return
 			}
 
-			amount, err := s.transactionRepo.GetSpendingByCategoryAndPeriod(ctx, cat, from, to)
+			amount, err := s.transactionRepo.GetSpendingByCategoryAndPeriod(ctx, cat, from, to, currency, tags)
 			results <- struct {
 				category string
-				amount   float64
+				amount   domain.Money
 				err      error
 			}{cat, amount, err}
 		}(category)
@@ -300,28 +1196,6 @@ func (s *ledgerService) heartbeat(ctx context.Context, totalCategories int) {
 	}
 }
 
-func (s *ledgerService) calculateCategorySpending(ctx context.Context, category string, from, to time.Time) (float64, error) {
-	transactions, err := s.transactionRepo.List(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get transactions: %w", err)
-	}
-
-	var total float64
-	for _, tx := range transactions {
-		select {
-		case <-ctx.Done():
-			return 0, ctx.Err()
-		default:
-		}
-
-		if tx.Category == category && !tx.Date.Before(from) && !tx.Date.After(to) {
-			total += tx.Amount
-		}
-	}
-
-	return total, nil
-}
-
 func (s *ledgerService) CreateTransactionsBulk(ctx context.Context, req domain.BulkTransactionRequest, workers int) (*domain.BulkTransactionResponse, error) {
 	total := len(req.Transactions)
 	if total == 0 {
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ledger/domain"
+	"log"
+	"time"
+)
+
+func (s *ledgerService) CreateRecurring(ctx context.Context, req domain.CreateRecurringRequest) (*domain.RecurringResponse, error) {
+	recurring := req.ToEntity()
+	if err := recurring.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+	if err := s.validateTransactionRequest(recurring.Transaction(recurring.StartsOn)); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	if err := s.recurringRepo.Create(ctx, &recurring); err != nil {
+		return nil, fmt.Errorf("failed to create recurring transaction: %w", err)
+	}
+
+	response := domain.RecurringResponseFromEntity(recurring)
+	return &response, nil
+}
+
+func (s *ledgerService) ListRecurring(ctx context.Context) ([]domain.RecurringResponse, error) {
+	recurrings, err := s.recurringRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring transactions: %w", err)
+	}
+
+	responses := make([]domain.RecurringResponse, len(recurrings))
+	for i, recurring := range recurrings {
+		responses[i] = domain.RecurringResponseFromEntity(recurring)
+	}
+
+	return responses, nil
+}
+
+// DeleteRecurring останавливает проведение; уже созданные транзакции остаются.
+func (s *ledgerService) DeleteRecurring(ctx context.Context, id int) error {
+	return s.recurringRepo.Deactivate(ctx, id)
+}
+
+func (s *ledgerService) ListOccurrences(ctx context.Context, req domain.ListOccurrencesRequest) ([]domain.OccurrenceResponse, error) {
+	switch req.Status {
+	case "", domain.OccurrencePending, domain.OccurrencePosted, domain.OccurrenceRejected:
+	default:
+		return nil, fmt.Errorf("%w: status must be 'pending', 'posted' or 'rejected'", domain.ErrValidation)
+	}
+
+	occurrences, err := s.recurringRepo.Occurrences(ctx, req.RecurringID, req.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list occurrences: %w", err)
+	}
+
+	responses := make([]domain.OccurrenceResponse, len(occurrences))
+	for i, occurrence := range occurrences {
+		responses[i] = domain.OccurrenceResponseFromEntity(occurrence)
+	}
+
+	return responses, nil
+}
+
+// PostDueRecurring проводит через CreateTransaction все наступившие к моменту at и ещё
+// не проведённые даты действующих шаблонов, включая пропущенные во время простоя.
+// Дата занимается в recurring_occurrences в одной транзакции БД с созданием транзакции
+// и итогом проведения, поэтому повторно не проводится. Отказ бюджета или проверки
+// сохраняется как rejected; при прочих ошибках, остановке или падении процесса
+// транзакция БД откатывается, и дата проводится при следующем запуске.
+func (s *ledgerService) PostDueRecurring(ctx context.Context, at time.Time) (int, error) {
+	recurrings, err := s.recurringRepo.Active(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recurring transactions: %w", err)
+	}
+
+	posted := 0
+	var errs []error
+	for _, recurring := range recurrings {
+		count, err := s.postRecurring(ctx, recurring, at)
+		posted += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recurring transaction %d: %w", recurring.ID, err))
+		}
+	}
+
+	return posted, errors.Join(errs...)
+}
+
+func (s *ledgerService) postRecurring(ctx context.Context, recurring domain.RecurringTransaction, at time.Time) (int, error) {
+	existing, err := s.recurringRepo.OccurrenceDates(ctx, recurring.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	done := make(map[string]bool, len(existing))
+	for _, date := range existing {
+		done[date.Format("2006-01-02")] = true
+	}
+
+	posted := 0
+	for _, due := range recurring.DueDates(recurring.StartsOn, at) {
+		if ctx.Err() != nil {
+			return posted, ctx.Err()
+		}
+		if done[due.Format("2006-01-02")] {
+			continue
+		}
+
+		ok, err := s.postOccurrence(ctx, recurring, due)
+		if err != nil {
+			return posted, err
+		}
+		if ok {
+			posted++
+		}
+	}
+
+	return posted, nil
+}
+
+// postOccurrence проводит дату due и возвращает true, если транзакция создана.
+func (s *ledgerService) postOccurrence(ctx context.Context, recurring domain.RecurringTransaction, due time.Time) (bool, error) {
+	var posted bool
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		posted = false
+
+		id, claimed, err := s.recurringRepo.ClaimOccurrence(ctx, recurring.ID, due)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil
+		}
+
+		occurrence := domain.Occurrence{ID: id, RecurringID: recurring.ID, DueOn: due}
+
+		// CreateTransaction выполняется в этой же транзакции БД. Отказ бюджета происходит
+		// до записи, поэтому проведение можно сохранить как rejected.
+		response, err := s.CreateTransaction(ctx, recurring.Transaction(due))
+		switch {
+		case err == nil:
+			occurrence.Status = domain.OccurrencePosted
+			occurrence.TransactionID = &response.ID
+		case isBudgetRejection(err):
+			occurrence.Status = domain.OccurrenceRejected
+			occurrence.Error = err.Error()
+		default:
+			return err
+		}
+
+		if err := s.recurringRepo.CompleteOccurrence(ctx, occurrence); err != nil {
+			return err
+		}
+
+		posted = occurrence.Status == domain.OccurrencePosted
+		if !posted {
+			log.Printf("Recurring transaction %d rejected for %s: %s", recurring.ID, due.Format("2006-01-02"), occurrence.Error)
+		}
+		return nil
+	})
+
+	return posted, err
+}
+
+// isBudgetRejection отличает окончательный отказ бюджета или проверки от временной ошибки.
+// Отсутствие курса тоже отказ: иначе дата проваливалась бы при каждом запуске и задерживала
+// все следующие даты шаблона, а причина не попадала бы в список проведений.
+func isBudgetRejection(err error) bool {
+	var poolErr *domain.PoolExceededError
+	return errors.Is(err, domain.ErrBudgetExceeded) ||
+		errors.Is(err, domain.ErrOverrideRequired) ||
+		errors.Is(err, domain.ErrBudgetNotFound) ||
+		errors.Is(err, domain.ErrExchangeRateNotFound) ||
+		errors.As(err, &poolErr) ||
+		errors.Is(err, domain.ErrValidation)
+}
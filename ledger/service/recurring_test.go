@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ledger/domain"
+	"testing"
+)
+
+func TestIsBudgetRejection(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "budget exceeded", err: domain.ErrBudgetExceeded, want: true},
+		{name: "pool exceeded", err: &domain.PoolExceededError{Pool: "Досуг"}, want: true},
+		{name: "missing exchange rate", err: fmt.Errorf("failed to get exchange rate: %w", domain.ErrExchangeRateNotFound), want: true},
+		{name: "validation", err: fmt.Errorf("%w: %w", domain.ErrValidation, errors.New("category is required")), want: true},
+		{name: "validation text without sentinel", err: errors.New("validation failed: category is required"), want: false},
+		{name: "database error", err: errors.New("connection refused"), want: false},
+		{name: "canceled", err: context.Canceled, want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isBudgetRejection(tc.err); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RecurringScheduler периодически проводит наступившие повторяющиеся транзакции.
+type RecurringScheduler struct {
+	service  LedgerService
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewRecurringScheduler(service LedgerService, interval time.Duration) *RecurringScheduler {
+	return &RecurringScheduler{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Start проводит транзакции сразу, догоняя пропущенные за время простоя, и затем
+// каждые interval до вызова Stop.
+func (s *RecurringScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(ctx)
+}
+
+// Stop останавливает планировщик и ждёт завершения текущего запуска.
+func (s *RecurringScheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+func (s *RecurringScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RecurringScheduler) tick(ctx context.Context) {
+	posted, err := s.service.PostDueRecurring(ctx, time.Now())
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Recurring transactions run failed: %v", err)
+	}
+	if posted > 0 {
+		log.Printf("Posted %d recurring transactions", posted)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ledger/domain"
+	"sync"
+	"testing"
+)
+
+func (r *memoryTransactions) GetForUpdate(ctx context.Context, id int) (*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.transactions) {
+		return nil, nil
+	}
+
+	transaction := r.transactions[id-1]
+	transaction.OverBudget = len(r.overspends[id]) > 0
+	return &transaction, nil
+}
+
+func (r *memoryTransactions) Update(ctx context.Context, transaction domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.transactions[transaction.ID-1] = transaction
+	return nil
+}
+
+// memoryOverspends хранит записи о превышении вместе с транзакциями, как одна БД.
+type memoryOverspends struct {
+	domain.OverspendRepository
+	transactions *memoryTransactions
+}
+
+func (r *memoryOverspends) Replace(ctx context.Context, transactionID int, overspends []domain.Overspend) error {
+	r.transactions.mu.Lock()
+	defer r.transactions.mu.Unlock()
+
+	if r.transactions.overspends == nil {
+		r.transactions.overspends = make(map[int][]domain.Overspend)
+	}
+	r.transactions.overspends[transactionID] = overspends
+	return nil
+}
+
+func TestUpdateTransactionReplacesOverspends(t *testing.T) {
+	t.Parallel()
+
+	transactions := &memoryTransactions{}
+	service := NewLedgerService(
+		transactions,
+		&memoryBudgets{budgets: map[string]domain.Budget{
+			"Кафе": {ID: 1, Category: "Кафе", Limit: 100000, Currency: domain.BaseCurrency, Period: domain.PeriodMonthly, OverspendPolicy: domain.OverspendAllow},
+		}},
+		&memoryCategories{},
+		&memoryPools{},
+		&memoryOverspends{transactions: transactions},
+		nil, nil, nil, nil, nil,
+		&memoryTransactor{locks: make(map[string]*sync.Mutex)},
+	)
+
+	ctx := context.Background()
+	amount := func(m domain.Money) *domain.Money { return &m }
+
+	created, err := service.CreateTransaction(ctx, domain.CreateTransactionRequest{Amount: 120000, Category: "Кафе"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !created.OverBudget {
+		t.Fatalf("Expected created transaction to be over budget")
+	}
+
+	steps := []struct {
+		name       string
+		amount     domain.Money
+		overBudget bool
+	}{
+		{name: "lowered below limit", amount: 80000, overBudget: false},
+		{name: "raised over limit", amount: 130000, overBudget: true},
+		{name: "raised again", amount: 140000, overBudget: true},
+		{name: "lowered but still over limit", amount: 110000, overBudget: true},
+	}
+
+	for _, step := range steps {
+		updated, err := service.UpdateTransaction(ctx, created.ID, domain.UpdateTransactionRequest{Amount: amount(step.amount)})
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", step.name, err)
+		}
+		if updated.OverBudget != step.overBudget {
+			t.Errorf("%s: expected over budget %v, got %v", step.name, step.overBudget, updated.OverBudget)
+		}
+
+		records := transactions.overspends[created.ID]
+		if !step.overBudget && len(records) != 0 {
+			t.Errorf("%s: expected no overspend records, got %d", step.name, len(records))
+		}
+		if step.overBudget && (len(records) != 1 || records[0].Spent != step.amount) {
+			t.Errorf("%s: expected one overspend record with spent %s, got %+v", step.name, step.amount, records)
+		}
+	}
+}
+
+func (r *memoryTransactions) Reverse(ctx context.Context, reversal domain.Transaction) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reversal.ID = len(r.transactions) + 1
+	r.transactions = append(r.transactions, reversal)
+	r.transactions[*reversal.ReversesID-1].ReversedByID = &reversal.ID
+	return reversal.ID, nil
+}
+
+func (r *memoryOverspends) Record(ctx context.Context, transactionID int, overspends []domain.Overspend) error {
+	return r.Replace(ctx, transactionID, overspends)
+}
+
+func TestRefundChangesCheckBudgets(t *testing.T) {
+	t.Parallel()
+
+	category := func(name string) *string { return &name }
+	amount := func(m domain.Money) *domain.Money { return &m }
+
+	testCases := []struct {
+		name    string
+		change  func(service LedgerService, refundID int) error
+		wantErr error
+	}{
+		{
+			name: "refund shrunk over limit",
+			change: func(service LedgerService, refundID int) error {
+				_, err := service.UpdateTransaction(context.Background(), refundID, domain.UpdateTransactionRequest{Amount: amount(100)})
+				return err
+			},
+			wantErr: domain.ErrBudgetExceeded,
+		},
+		{
+			name: "refund moved to another category",
+			change: func(service LedgerService, refundID int) error {
+				_, err := service.UpdateTransaction(context.Background(), refundID, domain.UpdateTransactionRequest{Category: category("Книги")})
+				return err
+			},
+			wantErr: domain.ErrBudgetExceeded,
+		},
+		{
+			name: "refund reversed",
+			change: func(service LedgerService, refundID int) error {
+				_, err := service.DeleteTransaction(context.Background(), refundID, domain.DeleteModeReversal)
+				return err
+			},
+			wantErr: domain.ErrBudgetExceeded,
+		},
+		{
+			name: "refund grown",
+			change: func(service LedgerService, refundID int) error {
+				_, err := service.UpdateTransaction(context.Background(), refundID, domain.UpdateTransactionRequest{Amount: amount(60000)})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			transactions := &memoryTransactions{}
+			service := NewLedgerService(
+				transactions,
+				&memoryBudgets{budgets: map[string]domain.Budget{
+					"Кафе": {ID: 1, Category: "Кафе", Limit: 100000, Currency: domain.BaseCurrency, Period: domain.PeriodMonthly, OverspendPolicy: domain.OverspendBlock},
+				}},
+				&memoryCategories{},
+				&memoryPools{},
+				&memoryOverspends{transactions: transactions},
+				nil, nil, nil, nil, nil,
+				&memoryTransactor{locks: make(map[string]*sync.Mutex)},
+			)
+
+			ctx := context.Background()
+			refund, err := service.CreateTransaction(ctx, domain.CreateTransactionRequest{Type: domain.TransactionRefund, Amount: 50000, Category: "Кафе"})
+			if err != nil {
+				t.Fatalf("Expected no error creating refund, got %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := service.CreateTransaction(ctx, domain.CreateTransactionRequest{Amount: 50000, Category: "Кафе"}); err != nil {
+					t.Fatalf("Expected no error creating expense, got %v", err)
+				}
+			}
+
+			if err := tc.change(service, refund.ID); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected %v, got %v", tc.wantErr, err)
+			}
+			if total := transactions.total("Кафе"); total > 100000 {
+				t.Errorf("Expected spending within limit 1000.00, got %s", total)
+			}
+		})
+	}
+}